@@ -47,6 +47,16 @@ import (
 //	}
 //
 // in the example above, Server, Logger and Config will be resolved against dependencies
+//
+// # Special parameters
+//
+// Self receives the current scope and dependencies, context.Context receives the context
+// given to SafeResolveContext (context.Background otherwise)
+//
+//	func(ctx context.Context, c Config) (*redis.Client, error) {
+//	  client := redis.NewClient(c.Options)
+//	  return client, client.Ping(ctx).Err()
+//	}
 func Make[T any](fn any, dependencies ...Retrievable) Submodule[T] {
 	return construct[T](fn, dependencies...)
 }
@@ -68,53 +78,85 @@ func Resolve[T any](t T, dependencies ...Retrievable) Submodule[T] {
 		panic(fmt.Sprintf("only struct or struct pointer : %v", tt.String()))
 	}
 
-	return construct[T](func(self Self) T {
+	m := construct[T](func(self Self) (T, error) {
 		x, e := resolveEmbedded(self.Scope, tt, reflect.ValueOf(t), self.Dependencies)
 
 		if e != nil {
-			panic(e)
+			var zero T
+			return zero, e
 		}
 
-		return x.Interface().(T)
-	}, dependencies...)
+		return x.Interface().(T), nil
+	}, dependencies...).(*submodule[T])
+
+	m.slots = fieldSlotsOf(0, tt)
+	m.src = calledFrom("submodule.Resolve")
+	return m
 }
 
 // Provide value as it is. Good to setup configurations, keeping types etc
 // as is
 func Value[T any](t T) Submodule[T] {
-	return construct[T](func() T {
+	m := construct[T](func() T {
 		return t
-	})
+	}).(*submodule[T])
+
+	m.src = calledFrom("submodule.Value")
+	return m
 }
 
 // Group groups submodules and re-advertise as a single value
 func Group[T any](s ...Retrievable) Submodule[[]T] {
-	return construct[[]T](func(self Self) []T {
+	opts, s := splitOpts(s)
+	m := construct[[]T](func(self Self) ([]T, error) {
 		var v []T
 		for _, submodule := range s {
 			t, e := submodule.retrieve(self.Scope)
 			if e != nil {
-				panic(e)
+				return nil, e
 			}
 
 			v = append(v, t.(T))
 		}
 
-		return v
-	})
+		return v, nil
+	}).(*submodule[[]T])
+
+	m.opts = opts
+	m.src = calledFrom("submodule.Group")
+
+	for _, r := range s {
+		m.slots = append(m.slots, slot{typ: r.provides(), dep: r})
+	}
+	return m
 }
 
 // Special type to facitliate dependency injection by struct. Meant to be embed
 type In struct {
 }
 
+// Find lists the resolved values assignable to T, the ones of the parent scopes included.
+// Each submodule is found once, with the value the scope would resolve it to
 func Find[T any](i []T, is Scope) []T {
 	t := reflect.TypeOf(i).Elem()
-	s := is.(*scope)
+	s := trace(is).scope
+
+	seen := map[Retrievable]bool{}
+	for _, c := range s.chain() {
+		c.mu.Lock()
+		var keys []Retrievable
+		for k := range c.values {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+		c.mu.Unlock()
 
-	for _, m := range s.values {
-		if m.value.Type().AssignableTo(t) {
-			i = append(i, m.value.Interface().(T))
+		for _, k := range keys {
+			if v, ok := s.lookup(k); ok && v.value.IsValid() && v.value.Type().AssignableTo(t) {
+				i = append(i, v.value.Interface().(T))
+			}
 		}
 	}
 
@@ -1,23 +1,41 @@
 package submodule
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
+	"slices"
+	"sync/atomic"
+	"time"
 )
 
 var inType = reflect.TypeOf(In{})
 var selfType = reflect.TypeOf(Self{})
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 
 type submodule[T any] struct {
 	input        any
 	provideType  reflect.Type
 	dependencies []Retrievable
+	slots        []slot
+	opts         SubmoduleOpts
+	src          source
+	// the modifiable submodule built by the submodule, if any
+	outer Retrievable
+	// dependencies by precedence, when modifiers come before them
+	ranked tiers
 }
 
 // Non generic representation of a submodule
 type Retrievable interface {
 	retrieve(Scope) (any, error)
 	canResolve(reflect.Type) bool
+	provides() reflect.Type
+	links() []link
+	options() SubmoduleOpts
+	source() source
 }
 
 // Submodule is a container holding a factory and its meta information
@@ -26,10 +44,15 @@ type Submodule[T any] interface {
 	Retrievable
 
 	// Substitute the implementation with another submodule. Very useful for testing as well as changing reusable graph
-	// Be warned that this may cause a circular dependency
+	// The submodule keeps its name, lifetime and pin, other options are those of the substitute
+	// Be warned that this may cause a circular dependency, resolving it will fail with a *CycleError
 	Substitute(Submodule[T])
 
+	// Same as Substitute, but only for resolutions in the scope and its children
+	SubstituteWith(Scope, Submodule[T])
+
 	// SafeResolve will resolve the submodule against the global scope and giving out errors of the factory and all of its dependencies
+	// A panicking factory is recovered, its *PanicError is cached in the scope just like a returned error
 	SafeResolve() (T, error)
 
 	// Resolve will resolve the submodule against the global scope. If the factory function or any of its dependencies return an error, panic will be called
@@ -44,6 +67,17 @@ type Submodule[T any] interface {
 	// Same as SafeResolve, but with a scope
 	SafeResolveWith(Scope) (T, error)
 
+	// Same as SafeResolveWith, the context is injected into factories declaring a context.Context parameter.
+	// Resolution is aborted once the context is done
+	SafeResolveContext(context.Context, Scope) (T, error)
+
+	// Same as SafeResolveContext, against the scope attached to the context with ContextWithScope,
+	// the global scope otherwise
+	SafeResolveFrom(context.Context) (T, error)
+
+	// Same as SafeResolveFrom, panics on error
+	ResolveFrom(context.Context) T
+
 	// Same as ResolveTo, but with a scope
 	ResolveToWith(Scope, T)
 }
@@ -57,6 +91,14 @@ func (s *submodule[T]) Substitute(other Submodule[T]) {
 	s.input = o.input
 	s.provideType = o.provideType
 	s.dependencies = o.dependencies
+	s.slots = o.slots
+	s.ranked = o.ranked
+	s.src = o.src
+
+	// the substitute takes the place of the submodule, which keeps its name and where its values live
+	opts := o.opts
+	opts.name, opts.lifetime, opts.pin = s.opts.name, s.opts.lifetime, s.opts.pin
+	s.opts = opts
 }
 
 func (s *submodule[T]) SafeResolve() (t T, e error) {
@@ -83,53 +125,56 @@ func (s *submodule[T]) SafeResolveWith(as Scope) (t T, e error) {
 		scope = as
 	}
 
-	var v *value
-	if scope.has(s) {
-		v = scope.get(s)
-		logger().Debug("cache hit", "targetType", s.provideType)
-	} else {
-		inputType := reflect.TypeOf(s.input)
+	f := trace(scope)
+	store := f.scope.owner(s)
 
-		argsTypes := make([]reflect.Type, inputType.NumIn())
-		args := make([]reflect.Value, inputType.NumIn())
+	if store.isDisposed() {
+		return t, failure(Canceled, f, ErrScopeDisposed, Step{Type: s.provideType, Field: f.field})
+	}
 
-		for i := 0; i < inputType.NumIn(); i++ {
-			argsTypes[i] = inputType.In(i)
+	if i := slices.IndexFunc(f.path, func(p step) bool { return p.r == s }); i >= 0 && !store.has(s) {
+		return t, newCycleError(append(slices.Clip(f.path[i:]), step{r: s}))
+	}
 
-			if isSelf(argsTypes[i]) {
-				args[i] = reflect.ValueOf(Self{
-					Scope:        scope,
-					Dependencies: s.dependencies,
-				})
-				continue
-			}
+	if n := len(f.path); n > 0 {
+		f.scope.depend(f.path[n-1].r, s)
+	}
 
-			v, err := resolveType(scope, argsTypes[i], s.dependencies)
-			if err != nil {
-				return t, err
-			}
+	child := &frame{
+		scope: store,
+		ctx:   f.ctx,
+		path:  append(slices.Clip(f.path), step{r: s, field: f.field}),
+	}
 
-			args[i] = v
-		}
+	// the value of s is built by its substitute in the scope, if any
+	impl := s
+	if o, ok := store.override(s).with.(*submodule[T]); ok {
+		impl = o
+	}
 
-		var result []reflect.Value
-		if inputType.IsVariadic() {
-			result = reflect.ValueOf(s.input).CallSlice(args)
-		} else {
-			result = reflect.ValueOf(s.input).Call(args)
+	build := func() (*value, bool) {
+		if since, deadline, ok := impl.deadline(store); ok {
+			return impl.buildWithin(child, since, deadline)
 		}
-		if len(result) == 1 {
-			v = scope.initValue(s, result[0])
-		} else {
-			v = scope.initValue(s, result[0])
-			if !result[1].IsNil() {
-				v.e = result[1]
-			}
+		return impl.build(child)
+	}
+
+	var v *value
+	var err error
+	if s.opts.lifetime == Transient && !store.has(s) {
+		if err = f.ctx.Err(); err == nil {
+			v, _ = build()
+			v = store.decorate(v)
 		}
+	} else {
+		v, err = store.resolve(child, s, build)
+	}
+	if err != nil {
+		return t, failure(Canceled, f, err, Step{Type: s.provideType, Field: f.field})
 	}
 
 	if v.e.IsValid() {
-		return t, v.e.Interface().(error)
+		return t, located(child, v.e.Interface().(error))
 	}
 
 	if v.value.IsZero() {
@@ -139,6 +184,162 @@ func (s *submodule[T]) SafeResolveWith(as Scope) (t T, e error) {
 	return v.value.Interface().(T), nil
 }
 
+// build calls the factory with its dependencies resolved against the frame.
+// Factory results are cached, failures to resolve a dependency and cancellations are only reported
+func (s *submodule[T]) build(f *frame) (*value, bool) {
+	inputType := reflect.TypeOf(s.input)
+
+	// factories may keep Self.Scope, it must not hold on to the caller context past the build
+	self := &frame{scope: f.scope, ctx: f.ctx, path: f.path, field: f.field, over: new(atomic.Bool)}
+	defer self.over.Store(true)
+
+	args, err := resolveEach(f, inputType.NumIn(), func(i int) (reflect.Value, error) {
+		t := inputType.In(i)
+
+		if isSelf(t) {
+			return reflect.ValueOf(Self{
+				Scope:        self,
+				Dependencies: s.dependencies,
+			}), nil
+		}
+
+		if isContext(t) {
+			return reflect.ValueOf(&f.ctx).Elem(), nil
+		}
+
+		return resolveSlot(f, paramSlot(i, t, s.opts.params), s.tiers())
+	})
+	if err != nil {
+		return &value{e: reflect.ValueOf(err)}, false
+	}
+
+	start := time.Now()
+	result, err := s.invoke(args)
+	took := time.Since(start)
+	if err != nil {
+		return &value{e: reflect.ValueOf(bare(FactoryPanic, err)), took: took}, true
+	}
+
+	v := &value{value: result[0], took: took}
+	if len(result) == 2 && !result[1].IsNil() {
+		err := result[1].Interface().(error)
+
+		// the factory gave up because the caller did, the next caller deserves another try
+		if ctxErr := f.ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return &value{e: reflect.ValueOf(failure(Canceled, f, err))}, false
+		}
+
+		v.e = reflect.ValueOf(bare(FactoryError, err))
+	}
+
+	return v, true
+}
+
+// deadline is the earliest of the submodule timeout and the scope startup budget,
+// since is when the time counted against it started
+func (s *submodule[T]) deadline(scope *scope) (since, deadline time.Time, ok bool) {
+	since, deadline, ok = scope.budget()
+	if s.opts.timeout > 0 {
+		now := time.Now()
+		if d := now.Add(s.opts.timeout); !ok || d.Before(deadline) {
+			since, deadline, ok = now, d, true
+		}
+	}
+	return since, deadline, ok
+}
+
+// buildWithin builds the submodule in its own goroutine and gives up once the deadline is reached.
+// The factory context is done at the deadline as well, so context aware factories can stop early.
+// Timeouts report the time spent since the given start
+func (s *submodule[T]) buildWithin(f *frame, since, deadline time.Time) (*value, bool) {
+	ctx, cancel := context.WithDeadline(f.ctx, deadline)
+	defer cancel()
+
+	type built struct {
+		v     *value
+		cache bool
+	}
+
+	done := make(chan built, 1)
+	go func() {
+		v, cache := s.build(&frame{scope: f.scope, ctx: ctx, path: f.path, field: f.field})
+		done <- built{v, cache}
+	}()
+
+	select {
+	case b := <-done:
+		if b.cache || ctx.Err() == nil {
+			return b.v, b.cache
+		}
+		if f.ctx.Err() == nil && isTimeout(b.v) {
+			return b.v, true
+		}
+	case <-ctx.Done():
+	}
+
+	if err := f.ctx.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return &value{e: reflect.ValueOf(failure(Canceled, f, err))}, false
+	}
+
+	// running out of the caller's time is not a failure of the submodule itself, it is not cached
+	own := !time.Now().Before(deadline)
+	return &value{e: reflect.ValueOf(bare(Timeout, &TimeoutError{
+		Type:  f.scope.stalled(f.path).provides(),
+		After: time.Since(since),
+	}))}, own
+}
+
+// invoke calls the factory, a panic is recovered into a *PanicError
+func (s *submodule[T]) invoke(args []reflect.Value) (result []reflect.Value, e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e = &PanicError{
+				Type:  s.provideType,
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+
+	if reflect.TypeOf(s.input).IsVariadic() {
+		return reflect.ValueOf(s.input).CallSlice(args), nil
+	}
+
+	return reflect.ValueOf(s.input).Call(args), nil
+}
+
+func (s *submodule[T]) SafeResolveContext(ctx context.Context, as Scope) (T, error) {
+	if as == nil {
+		as = globalScope
+	}
+
+	f := trace(as)
+	return s.SafeResolveWith(&frame{
+		scope: f.scope,
+		ctx:   ctx,
+		path:  f.path,
+		field: f.field,
+	})
+}
+
+func (s *submodule[T]) SafeResolveFrom(ctx context.Context) (T, error) {
+	scope, ok := ScopeFromContext(ctx)
+	if !ok {
+		scope = globalScope
+	}
+
+	return s.SafeResolveContext(ctx, scope)
+}
+
+func (s *submodule[T]) ResolveFrom(ctx context.Context) T {
+	t, e := s.SafeResolveFrom(ctx)
+	if e != nil {
+		panic(e)
+	}
+
+	return t
+}
+
 func (s *submodule[T]) Resolve() T {
 	r, e := s.SafeResolve()
 
@@ -165,6 +366,33 @@ func (s *submodule[T]) canResolve(key reflect.Type) bool {
 	return s.provideType.AssignableTo(key)
 }
 
+func (s *submodule[T]) provides() reflect.Type {
+	return s.provideType
+}
+
+func (s *submodule[T]) links() []link {
+	if s.outer != nil {
+		return s.outer.links()
+	}
+	return linksOf(s.slots, s.tiers(), s.opts.strictly())
+}
+
+// tiers groups the dependencies by precedence
+func (s *submodule[T]) tiers() tiers {
+	if s.ranked != nil {
+		return s.ranked
+	}
+	return tiers{s.dependencies}
+}
+
+func (s *submodule[T]) options() SubmoduleOpts {
+	return s.opts
+}
+
+func (s *submodule[T]) source() source {
+	return s.src
+}
+
 func validateInput(input any, isProvider bool) error {
 	inputType := reflect.TypeOf(input)
 
@@ -201,6 +429,11 @@ func construct[T any](
 	input any,
 	dependencies ...Retrievable,
 ) Submodule[T] {
+	opts, dependencies := splitOpts(dependencies)
+	return newSubmodule[T](input, opts, dependencies)
+}
+
+func newSubmodule[T any](input any, opts SubmoduleOpts, dependencies []Retrievable) *submodule[T] {
 	inputType := reflect.TypeOf(input)
 
 	if err := validateInput(input, true); err != nil {
@@ -236,64 +469,48 @@ func construct[T any](
 	}
 
 	// check feasibility
-	for i := 0; i < inputType.NumIn(); i++ {
-		canResolve := false
-
-		pt := inputType.In(i)
-		if isSelf(pt) {
-			continue
+	slots := slotsOf(inputType, opts.params)
+	for _, l := range linksOf(slots, tiers{dependencies}, opts.strictly()) {
+		if len(l.candidates) > 1 && opts.strictly() {
+			panic(
+				fmt.Sprintf(
+					"ambiguous dependency for type: %s. \n %s",
+					inputType.String(),
+					l.ambiguity(l.candidates),
+				),
+			)
 		}
 
-		if isInEmbedded(pt) {
-			for fi := 0; fi < pt.NumField(); fi++ {
-				f := pt.Field(fi)
-
-				if f.Type == inType {
-					continue
-				}
-
-				for _, d := range dependencies {
-					if d.canResolve(f.Type) {
-						canResolve = true
-						break
-					}
-				}
-
-				if !canResolve {
-					panic(
-						fmt.Sprintf(
-							"unable to resolve dependency for type: %s. \n Unable to resolve: %s of %s",
-							inputType.String(),
-							f.Type.String(),
-							pt.String(),
-						),
-					)
-				}
-			}
+		if l.target != nil || l.optional {
 			continue
 		}
 
-		for _, d := range dependencies {
-			if d.canResolve(pt) {
-				canResolve = true
-				break
-			}
-		}
-
-		if !canResolve {
+		if l.field != "" {
 			panic(
 				fmt.Sprintf(
-					"unable to resolve dependency for type: %s. \n Unable to resolve: %s",
+					"unable to resolve dependency for type: %s. \n Unable to resolve: %s of %s",
 					inputType.String(),
-					pt.String(),
+					l.slot.String(),
+					inputType.In(l.index).String(),
 				),
 			)
 		}
+
+		panic(
+			fmt.Sprintf(
+				"unable to resolve dependency for type: %s. \n Unable to resolve: %s",
+				inputType.String(),
+				l.slot.String(),
+			),
+		)
 	}
 
 	return &submodule[T]{
 		input:        input,
 		provideType:  provideType,
 		dependencies: dependencies,
+		slots:        slots,
+		opts:         opts,
+		src:          sourceOf(input),
 	}
 }
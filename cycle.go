@@ -0,0 +1,53 @@
+package submodule
+
+import "slices"
+
+// DetectCycle walks the dependency graph of the given submodules without calling any factory
+// and reports the first circular dependency found as a *CycleError.
+// Useful to fail fast at startup or in a unit test, instead of at the first resolution
+func DetectCycle(roots ...Retrievable) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := make(map[Retrievable]int)
+	var path []step
+
+	var visit func(r Retrievable) error
+	visit = func(r Retrievable) error {
+		switch state[r] {
+		case visited:
+			return nil
+		case visiting:
+			i := slices.IndexFunc(path, func(s step) bool { return s.r == r })
+			return newCycleError(append(slices.Clip(path[i:]), step{r: r}))
+		}
+
+		state[r] = visiting
+		path = append(path, step{r: r})
+
+		for _, l := range r.links() {
+			// lazy dependencies are resolved once construction is over, they do not take part in cycles
+			if l.target == nil || l.lazy {
+				continue
+			}
+
+			if err := visit(l.target); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[r] = visited
+		return nil
+	}
+
+	for _, r := range roots {
+		if err := visit(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
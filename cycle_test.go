@@ -0,0 +1,92 @@
+package submodule_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	cycleServer struct{}
+	cycleLogger struct{}
+	cycleConfig struct{}
+)
+
+func TestCycle(t *testing.T) {
+	t.Run("substitute creating a cycle fails fast", func(t *testing.T) {
+		logger := submodule.Make[*cycleLogger](func() *cycleLogger {
+			return &cycleLogger{}
+		})
+
+		server := submodule.Make[*cycleServer](func(l *cycleLogger) *cycleServer {
+			return &cycleServer{}
+		}, logger)
+
+		logger.Substitute(submodule.Make[*cycleLogger](func(s *cycleServer) *cycleLogger {
+			return &cycleLogger{}
+		}, server))
+
+		_, e := server.SafeResolveWith(submodule.CreateScope())
+
+		var ce *submodule.CycleError
+		require.True(t, errors.As(e, &ce))
+		assert.Len(t, ce.Path, 3)
+		assert.Equal(t,
+			"circular dependency detected: *submodule_test.cycleServer -> *submodule_test.cycleLogger -> *submodule_test.cycleServer",
+			ce.Error(),
+		)
+
+		require.ErrorAs(t, submodule.DetectCycle(server), &ce)
+		assert.Equal(t, "*submodule_test.cycleServer", ce.Path[0].String())
+	})
+
+	t.Run("modifier creating a cycle fails fast", func(t *testing.T) {
+		config := submodule.Value(cycleConfig{})
+
+		logger := submodule.MakeModifiable[*cycleLogger](func(c cycleConfig) *cycleLogger {
+			return &cycleLogger{}
+		}, config)
+
+		server := submodule.Make[*cycleServer](func(l *cycleLogger) *cycleServer {
+			return &cycleServer{}
+		}, logger)
+
+		require.NoError(t, submodule.DetectCycle(server))
+
+		logger.Append(submodule.Make[cycleConfig](func(s *cycleServer) cycleConfig {
+			return cycleConfig{}
+		}, server))
+
+		_, e := server.SafeResolveWith(submodule.CreateScope())
+
+		var ce *submodule.CycleError
+		require.ErrorAs(t, e, &ce)
+		assert.Equal(t,
+			"circular dependency detected: *submodule_test.cycleServer -> *submodule_test.cycleLogger -> submodule_test.cycleConfig -> *submodule_test.cycleServer",
+			ce.Error(),
+		)
+
+		require.ErrorAs(t, submodule.DetectCycle(server), &ce)
+		assert.Len(t, ce.Path, 4)
+	})
+
+	t.Run("shared dependencies are not a cycle", func(t *testing.T) {
+		config := submodule.Value(cycleConfig{})
+
+		logger := submodule.Make[*cycleLogger](func(c cycleConfig) *cycleLogger {
+			return &cycleLogger{}
+		}, config)
+
+		server := submodule.Make[*cycleServer](func(c cycleConfig, l *cycleLogger) *cycleServer {
+			return &cycleServer{}
+		}, config, logger)
+
+		assert.NoError(t, submodule.DetectCycle(server))
+
+		_, e := server.SafeResolveWith(submodule.CreateScope())
+		assert.NoError(t, e)
+	})
+}
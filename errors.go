@@ -0,0 +1,192 @@
+package submodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ErrScopeDisposed is reported when resolving against a disposed scope
+var ErrScopeDisposed = errors.New("scope is disposed")
+
+// CycleError is reported when a submodule depends on itself, directly or through its dependencies.
+// Path lists the provided type of each submodule in the loop, starting and ending with the same one
+type CycleError struct {
+	Path []reflect.Type
+}
+
+func newCycleError(loop []step) *CycleError {
+	path := make([]reflect.Type, len(loop))
+	for i, s := range loop {
+		path[i] = s.r.provides()
+	}
+	return &CycleError{Path: path}
+}
+
+func (e *CycleError) Error() string {
+	names := make([]string, len(e.Path))
+	for i, t := range e.Path {
+		names[i] = t.String()
+	}
+	return "circular dependency detected: " + strings.Join(names, " -> ")
+}
+
+// ErrorKind tells what went wrong while resolving a submodule
+type ErrorKind int
+
+const (
+	// a factory requires a value that none of the dependencies can provide
+	MissingDependency ErrorKind = iota + 1
+	// a factory returned an error
+	FactoryError
+	// a factory panicked
+	FactoryPanic
+	// the context of the resolution is done
+	Canceled
+	// a submodule was not built in time
+	Timeout
+	// more than one dependency can provide a value a factory requires
+	AmbiguousDependency
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case MissingDependency:
+		return "missing dependency"
+	case FactoryError:
+		return "factory error"
+	case FactoryPanic:
+		return "factory panic"
+	case Canceled:
+		return "resolution canceled"
+	case Timeout:
+		return "resolution timeout"
+	case AmbiguousDependency:
+		return "ambiguous dependency"
+	default:
+		return "unknown error"
+	}
+}
+
+// Step is a submodule on a resolution path.
+// Field is set when the submodule was injected into a field of the previous step, via an In struct or Resolve[T]
+type Step struct {
+	Type  reflect.Type
+	Field string
+}
+
+// ResolutionError is reported when a submodule, or any submodule it depends on, fails to resolve.
+// Path goes from the submodule being resolved down to the failing one.
+// For a missing dependency, the last step is the type that could not be resolved.
+// Err is the original error, errors.Is and errors.As see through ResolutionError
+type ResolutionError struct {
+	Kind ErrorKind
+	Path []Step
+	Err  error
+}
+
+// failure wraps err with the resolution path of the scope.
+// Errors which already carry their path are passed through untouched
+func failure(kind ErrorKind, scope Scope, err error, tail ...Step) error {
+	switch err.(type) {
+	case *ResolutionError, *CycleError:
+		return err
+	}
+
+	path := trace(scope).path
+	steps := make([]Step, 0, len(path)+len(tail))
+	for _, s := range path {
+		steps = append(steps, Step{Type: s.r.provides(), Field: s.field})
+	}
+
+	return &ResolutionError{
+		Kind: kind,
+		Path: append(steps, tail...),
+		Err:  err,
+	}
+}
+
+// bare is the failure of a submodule itself, it is cached without a path since every resolver reaches it through its own.
+// Errors which already carry their path are passed through untouched
+func bare(kind ErrorKind, err error) error {
+	switch err.(type) {
+	case *ResolutionError, *CycleError:
+		return err
+	}
+	return &ResolutionError{Kind: kind, Err: err}
+}
+
+// located gives a bare failure the resolution path of the scope it is returned to
+func located(scope Scope, err error) error {
+	if re, ok := err.(*ResolutionError); ok && re.Path == nil {
+		return failure(re.Kind, scope, re.Err)
+	}
+	return err
+}
+
+func (e *ResolutionError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Kind.String())
+	b.WriteString(" resolving ")
+	for i, s := range e.Path {
+		if i > 0 {
+			b.WriteString(" -> ")
+		}
+		if s.Field != "" {
+			b.WriteString(s.Field)
+			b.WriteString(" ")
+		}
+		b.WriteString(s.Type.String())
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError is reported when a factory panics.
+// Type is the type provided by the submodule, Value is what the factory panicked with
+// and Stack is the stack trace of the panicking goroutine
+type PanicError struct {
+	Type  reflect.Type
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("factory of %s panicked: %v\n\n%s", e.Type, e.Value, e.Stack)
+}
+
+// Unwrap exposes the panic value when the factory panicked with an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// TimeoutError is reported when a submodule is not built in time, because of its own timeout
+// or the startup budget of the scope.
+// Type is the type of the innermost submodule that was still being built
+type TimeoutError struct {
+	Type  reflect.Type
+	After time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s still resolving after %s", e.Type, e.After)
+}
+
+// Unwrap makes a timeout match context.DeadlineExceeded
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+func isTimeout(v *value) bool {
+	var re *ResolutionError
+	return v.e.IsValid() && errors.As(v.e.Interface().(error), &re) && re.Kind == Timeout
+}
@@ -0,0 +1,165 @@
+package submodule_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	errConfig  struct{}
+	errLogger  struct{}
+	errService struct {
+		Logger *errLogger
+		Name   string
+	}
+)
+
+var errDial = errors.New("dial failed")
+
+func TestResolutionError(t *testing.T) {
+	t.Run("factory error carries the path from the root", func(t *testing.T) {
+		config := submodule.Make[errConfig](func() (errConfig, error) {
+			return errConfig{}, errDial
+		})
+
+		logger := submodule.Make[*errLogger](func(c errConfig) *errLogger {
+			return &errLogger{}
+		}, config)
+
+		name := submodule.Value("svc")
+		service := submodule.Make[string](func(p struct {
+			submodule.In
+			Logger *errLogger
+			Name   string
+		}) string {
+			return p.Name
+		}, logger, name)
+
+		_, e := service.SafeResolveWith(submodule.CreateScope())
+		require.ErrorIs(t, e, errDial)
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.FactoryError, re.Kind)
+		assert.Equal(t, []submodule.Step{
+			{Type: reflect.TypeOf("")},
+			{Type: reflect.TypeOf(&errLogger{}), Field: "Logger"},
+			{Type: reflect.TypeOf(errConfig{})},
+		}, re.Path)
+		assert.Equal(t,
+			"factory error resolving string -> Logger *submodule_test.errLogger -> submodule_test.errConfig: dial failed",
+			e.Error(),
+		)
+	})
+
+	t.Run("cached factory error carries the path of each resolver", func(t *testing.T) {
+		config := submodule.Make[errConfig](func() (errConfig, error) {
+			return errConfig{}, errDial
+		})
+
+		logger := submodule.Make[*errLogger](func(c errConfig) *errLogger {
+			return &errLogger{}
+		}, config)
+		name := submodule.Make[string](func(c errConfig) string {
+			return "svc"
+		}, config)
+
+		scope := submodule.CreateScope()
+		_, e := logger.SafeResolveWith(scope)
+		assert.EqualError(t, e, "factory error resolving *submodule_test.errLogger -> submodule_test.errConfig: dial failed")
+
+		_, e = name.SafeResolveWith(scope)
+		assert.EqualError(t, e, "factory error resolving string -> submodule_test.errConfig: dial failed")
+
+		_, e = config.SafeResolveWith(scope)
+		assert.EqualError(t, e, "factory error resolving submodule_test.errConfig: dial failed")
+	})
+
+	t.Run("missing dependency names the field", func(t *testing.T) {
+		logger := submodule.Make[*errLogger](func() *errLogger {
+			return &errLogger{}
+		})
+
+		service := submodule.Resolve(&errService{}, logger)
+
+		_, e := service.SafeResolveWith(submodule.CreateScope())
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.MissingDependency, re.Kind)
+		assert.Equal(t, []submodule.Step{
+			{Type: reflect.TypeOf(&errService{})},
+			{Type: reflect.TypeOf(""), Field: "Name"},
+		}, re.Path)
+	})
+
+	t.Run("group reports the failing member", func(t *testing.T) {
+		ok := submodule.Value(errConfig{})
+		failing := submodule.Make[errConfig](func() (errConfig, error) {
+			return errConfig{}, errDial
+		})
+
+		_, e := submodule.Group[errConfig](ok, failing).SafeResolveWith(submodule.CreateScope())
+		require.ErrorIs(t, e, errDial)
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Len(t, re.Path, 2)
+		assert.Equal(t, reflect.TypeOf([]errConfig{}), re.Path[0].Type)
+	})
+}
+
+func TestPanicError(t *testing.T) {
+	t.Run("factory panic is recovered and cached", func(t *testing.T) {
+		calls := 0
+		config := submodule.Make[errConfig](func() errConfig {
+			calls++
+			panic("config file is missing")
+		})
+
+		logger := submodule.Make[*errLogger](func(c errConfig) *errLogger {
+			return &errLogger{}
+		}, config)
+
+		scope := submodule.CreateScope()
+		_, e := logger.SafeResolveWith(scope)
+
+		var pe *submodule.PanicError
+		require.ErrorAs(t, e, &pe)
+		assert.Equal(t, "config file is missing", pe.Value)
+		assert.Equal(t, reflect.TypeOf(errConfig{}), pe.Type)
+		assert.Contains(t, string(pe.Stack), "errors_test.go")
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.FactoryPanic, re.Kind)
+		assert.Len(t, re.Path, 2)
+
+		_, e = config.SafeResolveWith(scope)
+		require.ErrorAs(t, e, &pe)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("panicking with an error keeps it reachable", func(t *testing.T) {
+		failing := submodule.Make[errConfig](func() (errConfig, error) {
+			return errConfig{}, errDial
+		})
+
+		resolving := submodule.Make[*errLogger](func(self submodule.Self) *errLogger {
+			failing.ResolveWith(self.Scope)
+			return &errLogger{}
+		})
+
+		_, e := resolving.SafeResolveWith(submodule.CreateScope())
+		assert.ErrorIs(t, e, errDial)
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.FactoryPanic, re.Kind)
+	})
+}
@@ -0,0 +1,143 @@
+package submodule
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DOT renders the graph in the Graphviz DOT language.
+// Resolved nodes are filled green and errored ones red once the graph is annotated,
+// lazy dependencies are dashed and optional ones dotted
+func (g *Graph) DOT() string {
+	ids := g.ids()
+
+	var b strings.Builder
+	b.WriteString("digraph submodule {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, n := range g.Nodes {
+		attrs := []string{fmt.Sprintf("label=%q", strings.Join(n.label(), "\n"))}
+		switch n.State {
+		case Resolved:
+			attrs = append(attrs, `style=filled`, `fillcolor="#c8e6c9"`)
+		case Errored:
+			attrs = append(attrs, `style=filled`, `fillcolor="#ffcdd2"`)
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", ids[n], strings.Join(attrs, ", "))
+	}
+
+	for _, e := range g.Edges {
+		attrs := []string{fmt.Sprintf("label=%q", e.label())}
+		switch {
+		case e.Lazy:
+			attrs = append(attrs, "style=dashed")
+		case e.Optional:
+			attrs = append(attrs, "style=dotted")
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", ids[e.From], ids[e.To], strings.Join(attrs, ", "))
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+// Resolved and errored nodes get the resolved and errored classes once the graph is annotated,
+// lazy and optional dependencies are dotted
+func (g *Graph) Mermaid() string {
+	ids := g.ids()
+
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n], mermaidEscape(strings.Join(n.label(), "<br/>")))
+	}
+
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Lazy || e.Optional {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|\"%s\"| %s\n", ids[e.From], arrow, mermaidEscape(e.label()), ids[e.To])
+	}
+
+	var resolved, errored []string
+	for _, n := range g.Nodes {
+		switch n.State {
+		case Resolved:
+			resolved = append(resolved, ids[n])
+		case Errored:
+			errored = append(errored, ids[n])
+		}
+	}
+
+	if len(resolved) > 0 {
+		b.WriteString("  classDef resolved fill:#c8e6c9\n")
+		fmt.Fprintf(&b, "  class %s resolved\n", strings.Join(resolved, ","))
+	}
+	if len(errored) > 0 {
+		b.WriteString("  classDef errored fill:#ffcdd2\n")
+		fmt.Fprintf(&b, "  class %s errored\n", strings.Join(errored, ","))
+	}
+
+	return b.String()
+}
+
+func (g *Graph) ids() map[*Node]string {
+	ids := make(map[*Node]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
+
+// label lines of a node: its type, name and factory, then its state if annotated
+func (n *Node) label() []string {
+	lines := []string{n.Type.String()}
+	if n.Name != "" {
+		lines[0] += " named " + n.Name
+	}
+
+	if n.Func != "" {
+		lines = append(lines, n.Func[strings.LastIndex(n.Func, "/")+1:])
+	}
+
+	if n.Lifetime != Singleton {
+		lines = append(lines, n.Lifetime.String())
+	}
+
+	switch n.State {
+	case Unknown:
+	case Resolved, Errored:
+		lines = append(lines, fmt.Sprintf("%s in %s", n.State, n.Duration))
+	default:
+		lines = append(lines, n.State.String())
+	}
+
+	return lines
+}
+
+// label of an edge: the field it fills, or the parameter position
+func (e *Edge) label() string {
+	l := e.Field
+	if l == "" {
+		l = fmt.Sprintf("#%d", e.Param)
+	}
+
+	if e.Name != "" {
+		l += " named " + e.Name
+	}
+
+	if e.Lazy {
+		l += " (lazy)"
+	} else if e.Optional {
+		l += " (optional)"
+	}
+
+	return l
+}
+
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
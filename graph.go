@@ -0,0 +1,210 @@
+package submodule
+
+import (
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// source is where a submodule was declared
+type source struct {
+	fn   string
+	file string
+	line int
+}
+
+// sourceOf locates a factory function
+func sourceOf(fn any) source {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return source{}
+	}
+
+	file, line := f.FileLine(f.Entry())
+	return source{fn: f.Name(), file: file, line: line}
+}
+
+// calledFrom locates the code calling a constructor of this package, named fn
+func calledFrom(fn string) source {
+	_, file, line, _ := runtime.Caller(2)
+	return source{fn: fn, file: file, line: line}
+}
+
+// implemented by every ModifiableSubmodule
+type modifiable interface {
+	Append(submodule ...Retrievable)
+	Reset()
+}
+
+// Graph is the dependency graph declared by a set of root submodules.
+// Nodes are listed in depth first order from the roots, each submodule appears once
+type Graph struct {
+	Roots []*Node
+	Nodes []*Node
+	Edges []*Edge
+}
+
+// Node is a submodule of the graph
+type Node struct {
+	// type provided by the submodule
+	Type reflect.Type
+	// name given with WithName
+	Name string
+	// factory function, or the constructor of this package (submodule.Value, submodule.Resolve...) which made it
+	Func string
+	// where the factory function is defined, or where the constructor was called
+	File string
+	Line int
+
+	Lifetime   Lifetime
+	Modifiable bool
+
+	// filled by Annotate
+	State State
+	// time spent in the factory, dependencies excluded. Filled by Annotate
+	Duration time.Duration
+
+	submodule Retrievable
+}
+
+// State of a submodule in a scope
+type State int
+
+const (
+	// Unknown is the state of a node the graph was not annotated with
+	Unknown State = iota
+	Unresolved
+	Resolved
+	Errored
+)
+
+func (s State) String() string {
+	switch s {
+	case Unresolved:
+		return "unresolved"
+	case Resolved:
+		return "resolved"
+	case Errored:
+		return "errored"
+	default:
+		return "unknown"
+	}
+}
+
+// Edge links a submodule to the dependency filling one of its factory parameters or fields
+type Edge struct {
+	From *Node
+	To   *Node
+	// position of the factory parameter
+	Param int
+	// field of the In struct, or of the Resolve[T] struct. Empty for a plain parameter
+	Field string
+	// name asked for the dependency, if any
+	Name     string
+	Optional bool
+	Lazy     bool
+}
+
+// Inspect walks the dependencies of the given submodules without resolving them.
+// Modifiable submodules are inspected with their current modifiers
+func Inspect(roots ...Retrievable) *Graph {
+	g := &Graph{}
+	nodes := make(map[Retrievable]*Node)
+
+	var visit func(r Retrievable) *Node
+	visit = func(r Retrievable) *Node {
+		if n, ok := nodes[r]; ok {
+			return n
+		}
+
+		src := r.source()
+		opts := r.options()
+		_, isModifiable := r.(modifiable)
+
+		n := &Node{
+			Type:       r.provides(),
+			Name:       opts.name,
+			Func:       src.fn,
+			File:       src.file,
+			Line:       src.line,
+			Lifetime:   opts.lifetime,
+			Modifiable: isModifiable,
+			submodule:  r,
+		}
+		nodes[r] = n
+		g.Nodes = append(g.Nodes, n)
+
+		for _, l := range r.links() {
+			if l.target == nil {
+				continue
+			}
+
+			g.Edges = append(g.Edges, &Edge{
+				From:     n,
+				To:       visit(l.target),
+				Param:    l.index,
+				Field:    l.field,
+				Name:     l.name,
+				Optional: l.optional,
+				Lazy:     l.lazy,
+			})
+		}
+
+		return n
+	}
+
+	for _, r := range roots {
+		g.Roots = append(g.Roots, visit(r))
+	}
+
+	return g
+}
+
+// Dependencies lists the edges going out of a node
+func (g *Graph) Dependencies(n *Node) []*Edge {
+	var edges []*Edge
+	for _, e := range g.Edges {
+		if e.From == n {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Dependents lists the edges coming into a node
+func (g *Graph) Dependents(n *Node) []*Edge {
+	var edges []*Edge
+	for _, e := range g.Edges {
+		if e.To == n {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// Annotate fills the state of every node in the given scope, and how long its factory took.
+// Nothing is resolved in the process
+func (g *Graph) Annotate(s Scope) *Graph {
+	if s == nil {
+		s = globalScope
+	}
+
+	for _, n := range g.Nodes {
+		// values of a modifiable submodule are cached under the submodule it builds
+		key := keyOf(n.submodule)
+		store := trace(s).scope.owner(key)
+
+		n.State, n.Duration = Unresolved, 0
+		if !store.has(key) {
+			continue
+		}
+
+		v := store.get(key)
+		n.State, n.Duration = Resolved, v.took
+		if v.e.IsValid() {
+			n.State = Errored
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,154 @@
+package submodule_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	graphConfig struct{}
+	graphLogger struct{}
+	graphDb     struct{}
+	graphServer struct{}
+)
+
+func newGraphLogger(c graphConfig) *graphLogger {
+	return &graphLogger{}
+}
+
+func newGraphServer(p struct {
+	submodule.In
+	Logger *graphLogger
+	Db     submodule.Optional[*graphDb]
+}) *graphServer {
+	return &graphServer{}
+}
+
+func TestInspect(t *testing.T) {
+	config := submodule.Value(graphConfig{})
+	logger := submodule.MakeModifiable[*graphLogger](newGraphLogger, config, submodule.WithLifetime(submodule.Transient))
+	db := submodule.Make[*graphDb](func(c graphConfig) *graphDb {
+		return &graphDb{}
+	}, config, submodule.WithName("primary"))
+	server := submodule.Make[*graphServer](newGraphServer, logger, db)
+
+	g := submodule.Inspect(server)
+	require.Len(t, g.Roots, 1)
+	require.Len(t, g.Nodes, 4)
+	require.Len(t, g.Edges, 4)
+
+	t.Run("nodes describe the submodules", func(t *testing.T) {
+		root := g.Roots[0]
+		assert.Equal(t, "*submodule_test.graphServer", root.Type.String())
+		assert.Equal(t, "github.com/submodule-org/submodule.go/v2_test.newGraphServer", root.Func)
+		assert.Equal(t, "graph_test.go", filepath.Base(root.File))
+		assert.NotZero(t, root.Line)
+		assert.False(t, root.Modifiable)
+
+		l := g.Nodes[1]
+		assert.Equal(t, "*submodule_test.graphLogger", l.Type.String())
+		assert.True(t, l.Modifiable)
+		assert.Equal(t, submodule.Transient, l.Lifetime)
+
+		c := g.Nodes[2]
+		assert.Equal(t, "submodule.Value", c.Func)
+		assert.Equal(t, "graph_test.go", filepath.Base(c.File))
+
+		d := g.Nodes[3]
+		assert.Equal(t, "primary", d.Name)
+		assert.Equal(t, submodule.Singleton, d.Lifetime)
+	})
+
+	t.Run("edges tell which field or parameter they fill", func(t *testing.T) {
+		edges := g.Dependencies(g.Roots[0])
+		require.Len(t, edges, 2)
+
+		assert.Equal(t, "Logger", edges[0].Field)
+		assert.Same(t, g.Nodes[1], edges[0].To)
+
+		assert.Equal(t, "Db", edges[1].Field)
+		assert.True(t, edges[1].Optional)
+
+		assert.Len(t, g.Dependents(g.Nodes[2]), 2)
+		assert.Equal(t, 0, g.Dependencies(g.Nodes[1])[0].Param)
+	})
+
+	t.Run("modifiers show up in the graph", func(t *testing.T) {
+		logger.Append(submodule.Value(graphConfig{}))
+		defer logger.Reset()
+
+		g := submodule.Inspect(server)
+		assert.Len(t, g.Nodes, 5)
+	})
+
+	t.Run("modifiable roots are annotated", func(t *testing.T) {
+		cache := submodule.MakeModifiable[*graphDb](func(c graphConfig) *graphDb {
+			return &graphDb{}
+		}, config)
+
+		scope := submodule.CreateScope()
+		cache.ResolveWith(scope)
+
+		g := submodule.Inspect(cache).Annotate(scope)
+		assert.Equal(t, submodule.Resolved, g.Roots[0].State)
+		assert.Equal(t, submodule.Resolved, g.Nodes[1].State)
+	})
+}
+
+func TestExport(t *testing.T) {
+	config := submodule.Value(graphConfig{})
+	logger := submodule.Make[*graphLogger](newGraphLogger, config)
+	db := submodule.Make[*graphDb](func(c graphConfig) (*graphDb, error) {
+		return nil, errors.New("unreachable")
+	}, config, submodule.WithName("primary"))
+	server := submodule.Make[*graphServer](newGraphServer, logger, db)
+
+	t.Run("dot", func(t *testing.T) {
+		dot := submodule.Inspect(server).DOT()
+
+		assert.True(t, strings.HasPrefix(dot, "digraph submodule {\n"))
+		assert.Contains(t, dot, `n0 [label="*submodule_test.graphServer\nv2_test.newGraphServer"];`)
+		assert.Contains(t, dot, `n0 -> n1 [label="Logger"];`)
+		assert.Contains(t, dot, `n0 -> n3 [label="Db (optional)", style=dotted];`)
+		assert.Contains(t, dot, `n3 [label="*submodule_test.graphDb named primary`)
+		assert.NotContains(t, dot, "filled")
+	})
+
+	t.Run("mermaid", func(t *testing.T) {
+		mermaid := submodule.Inspect(server).Mermaid()
+
+		assert.True(t, strings.HasPrefix(mermaid, "graph TD\n"))
+		assert.Contains(t, mermaid, `n1 -->|"#0"| n2`)
+		assert.Contains(t, mermaid, `n0 -.->|"Db (optional)"| n3`)
+		assert.NotContains(t, mermaid, "classDef")
+	})
+
+	t.Run("annotated with a scope", func(t *testing.T) {
+		scope := submodule.CreateScope()
+		_, err := logger.SafeResolveWith(scope)
+		require.NoError(t, err)
+		_, err = db.SafeResolveWith(scope)
+		require.Error(t, err)
+
+		g := submodule.Inspect(server).Annotate(scope)
+		assert.Equal(t, submodule.Unresolved, g.Nodes[0].State)
+		assert.Equal(t, submodule.Resolved, g.Nodes[1].State)
+		assert.Equal(t, submodule.Resolved, g.Nodes[2].State)
+		assert.Equal(t, submodule.Errored, g.Nodes[3].State)
+
+		dot := g.DOT()
+		assert.Contains(t, dot, `unresolved"];`)
+		assert.Contains(t, dot, `fillcolor="#c8e6c9"`)
+		assert.Contains(t, dot, `fillcolor="#ffcdd2"`)
+
+		mermaid := g.Mermaid()
+		assert.Contains(t, mermaid, "class n1,n2 resolved\n")
+		assert.Contains(t, mermaid, "class n3 errored\n")
+	})
+}
@@ -0,0 +1,57 @@
+package submodule
+
+import (
+	"errors"
+	"reflect"
+)
+
+var lazyType = reflect.TypeOf((*lazy)(nil)).Elem()
+
+// implemented by every Lazy[T], to bind them without knowing T
+type lazy interface {
+	elem() reflect.Type
+	bind(scope Scope, target Retrievable) reflect.Value
+}
+
+// Lazy is a handle on a dependency which is only resolved when Get is called.
+// Declare Lazy[T] instead of T as a factory parameter, an In field or a Resolve[T] field.
+// It captures the scope of the factory and the dependency providing T.
+//
+// Lazy handles break construction order cycles and avoid building expensive clients that may never be used
+//
+//	var Cmd = submodule.Make[*Cmd](func(db submodule.Lazy[*sql.DB]) *Cmd {
+//	  return &Cmd{db: db}
+//	}, DbMod)
+//
+//	func (c *Cmd) Run() error {
+//	  db, e := c.db.Get()
+//	  ...
+//	}
+type Lazy[T any] struct {
+	scope  Scope
+	target Retrievable
+}
+
+// Get resolves the dependency against the captured scope
+func (l Lazy[T]) Get() (t T, e error) {
+	if l.target == nil {
+		return t, errors.New("lazy dependency is not bound to a scope")
+	}
+
+	v, e := l.target.retrieve(l.scope)
+	if e != nil || v == nil {
+		return t, e
+	}
+
+	return v.(T), nil
+}
+
+func (l Lazy[T]) elem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (l Lazy[T]) bind(scope Scope, target Retrievable) reflect.Value {
+	l.scope = scope
+	l.target = target
+	return reflect.ValueOf(l)
+}
@@ -0,0 +1,117 @@
+package submodule_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	lazyClient struct{}
+	lazyCmd    struct {
+		Client submodule.Lazy[*lazyClient]
+	}
+	lazyParent struct {
+		Child *lazyChild
+	}
+	lazyChild struct {
+		Parent submodule.Lazy[*lazyParent]
+	}
+)
+
+func TestLazy(t *testing.T) {
+	t.Run("dependency is resolved on Get", func(t *testing.T) {
+		built := 0
+		client := submodule.Make[*lazyClient](func() *lazyClient {
+			built++
+			return &lazyClient{}
+		})
+
+		cmd := submodule.Make[*lazyCmd](func(c submodule.Lazy[*lazyClient]) *lazyCmd {
+			return &lazyCmd{Client: c}
+		}, client)
+
+		scope := submodule.CreateScope()
+		c := cmd.ResolveWith(scope)
+		assert.Equal(t, 0, built)
+
+		x, e := c.Client.Get()
+		require.NoError(t, e)
+		assert.Equal(t, 1, built)
+		assert.Same(t, client.ResolveWith(scope), x)
+	})
+
+	t.Run("works with In and Resolve fields", func(t *testing.T) {
+		client := submodule.Make[*lazyClient](func() *lazyClient {
+			return &lazyClient{}
+		})
+
+		viaIn := submodule.Make[submodule.Lazy[*lazyClient]](func(p struct {
+			submodule.In
+			Client submodule.Lazy[*lazyClient]
+		}) submodule.Lazy[*lazyClient] {
+			return p.Client
+		}, client)
+
+		viaResolve := submodule.Resolve(&lazyCmd{}, client)
+
+		scope := submodule.CreateScope()
+		a, e := viaIn.ResolveWith(scope).Get()
+		require.NoError(t, e)
+		b, e := viaResolve.ResolveWith(scope).Client.Get()
+		require.NoError(t, e)
+		assert.Same(t, a, b)
+	})
+
+	t.Run("breaks construction order cycles", func(t *testing.T) {
+		var parent submodule.Submodule[*lazyParent]
+
+		child := submodule.Make[*lazyChild](func(p submodule.Lazy[*lazyParent]) *lazyChild {
+			return &lazyChild{Parent: p}
+		}, submodule.Make[*lazyParent](func() *lazyParent {
+			return nil
+		}))
+
+		parent = submodule.Resolve(&lazyParent{}, child)
+		child.Substitute(submodule.Make[*lazyChild](func(p submodule.Lazy[*lazyParent]) *lazyChild {
+			return &lazyChild{Parent: p}
+		}, parent))
+
+		require.NoError(t, submodule.DetectCycle(parent))
+
+		scope := submodule.CreateScope()
+		p, e := parent.SafeResolveWith(scope)
+		require.NoError(t, e)
+
+		back, e := p.Child.Parent.Get()
+		require.NoError(t, e)
+		assert.Same(t, p, back)
+	})
+
+	t.Run("getting during construction is still a cycle", func(t *testing.T) {
+		var parent submodule.Submodule[*lazyParent]
+
+		child := submodule.Make[*lazyChild](func() *lazyChild {
+			return &lazyChild{}
+		})
+
+		parent = submodule.Resolve(&lazyParent{}, child)
+		child.Substitute(submodule.Make[*lazyChild](func(p submodule.Lazy[*lazyParent]) (*lazyChild, error) {
+			_, e := p.Get()
+			return &lazyChild{Parent: p}, e
+		}, parent))
+
+		_, e := parent.SafeResolveWith(submodule.CreateScope())
+
+		var ce *submodule.CycleError
+		require.ErrorAs(t, e, &ce)
+	})
+
+	t.Run("unbound handle", func(t *testing.T) {
+		var l submodule.Lazy[*lazyClient]
+		_, e := l.Get()
+		assert.Error(t, e)
+	})
+}
@@ -0,0 +1,294 @@
+package submodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// hook is a start or stop function registered by the factory of a submodule
+type hook struct {
+	owner Retrievable
+	start func(context.Context) error
+	stop  func(context.Context) error
+}
+
+// OnStart registers a function to run when the App starts, once every root is resolved.
+// Start hooks run in dependency order, the hooks of a value run after those of its dependencies
+//
+//	var Consumer = submodule.Make[*Consumer](func(self submodule.Self, q *Queue) *Consumer {
+//	  c := NewConsumer(q)
+//	  self.OnStart(c.Start)
+//	  self.OnStop(c.Stop)
+//	  return c
+//	}, QueueMod)
+func (s Self) OnStart(fn func(context.Context) error) {
+	f := trace(s.Scope)
+	f.addHook(hook{owner: f.owner(), start: fn})
+}
+
+// OnStop registers a function to run when the App stops.
+// Stop hooks run in reverse dependency order, the hooks of a value run before those of its dependencies
+func (s Self) OnStop(fn func(context.Context) error) {
+	f := trace(s.Scope)
+	f.addHook(hook{owner: f.owner(), stop: fn})
+}
+
+// owner is the submodule being built in the frame
+func (f *frame) owner() Retrievable {
+	if len(f.path) == 0 {
+		return nil
+	}
+	return f.path[len(f.path)-1].r
+}
+
+func (s *scope) addHook(h hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = append(s.hooks, h)
+}
+
+// depend records that from resolved to while being built
+func (s *scope) depend(from, to Retrievable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Contains(s.edges[from], to) {
+		s.edges[from] = append(s.edges[from], to)
+	}
+}
+
+// dependencyOrder sorts the owners so that each one comes after the submodules it depends on,
+// directly or not. Owners unrelated to each other keep their order
+func (s *scope) dependencyOrder(owners []Retrievable) []Retrievable {
+	return dependencyOrder(owners, edgesOf([]*scope{s}))
+}
+
+// edgesOf merges the dependencies recorded in the scopes
+func edgesOf(scopes []*scope) map[Retrievable][]Retrievable {
+	edges := make(map[Retrievable][]Retrievable)
+	for _, s := range scopes {
+		s.mu.Lock()
+		for from, to := range s.edges {
+			for _, t := range to {
+				if !slices.Contains(edges[from], t) {
+					edges[from] = append(edges[from], t)
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+	return edges
+}
+
+func dependencyOrder(owners []Retrievable, edges map[Retrievable][]Retrievable) []Retrievable {
+	var ordered []Retrievable
+	seen := make(map[Retrievable]bool)
+
+	var visit func(r Retrievable)
+	visit = func(r Retrievable) {
+		if seen[r] {
+			return
+		}
+		seen[r] = true
+
+		for _, d := range edges[r] {
+			visit(d)
+		}
+		if slices.Contains(owners, r) {
+			ordered = append(ordered, r)
+		}
+	}
+
+	for _, o := range owners {
+		visit(o)
+	}
+	return ordered
+}
+
+// lifecycle groups the hooks by owner, in dependency order.
+// Hooks of values built in parent scopes, shared or root singletons, are part of it
+func (s *scope) lifecycle() [][]hook {
+	chain := s.chain()
+
+	var hooks []hook
+	for i := len(chain) - 1; i >= 0; i-- {
+		chain[i].mu.Lock()
+		hooks = append(hooks, chain[i].hooks...)
+		chain[i].mu.Unlock()
+	}
+
+	var owners []Retrievable
+	for _, h := range hooks {
+		if !slices.Contains(owners, h.owner) {
+			owners = append(owners, h.owner)
+		}
+	}
+
+	var groups [][]hook
+	for _, o := range dependencyOrder(owners, edgesOf(chain)) {
+		var group []hook
+		for _, h := range hooks {
+			if h.owner == o {
+				group = append(group, h)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// App resolves a set of submodules, starts them and stops them once asked to
+type App struct {
+	scope           Scope
+	roots           []Retrievable
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+type AppOpts struct {
+	scope           Scope
+	roots           []Retrievable
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+type AppOptsFn func(opts AppOpts) AppOpts
+
+// WithRoots lists the submodules the App runs, resolved in the given order
+func WithRoots(roots ...Retrievable) AppOptsFn {
+	return func(opts AppOpts) AppOpts {
+		opts.roots = append(opts.roots, roots...)
+		return opts
+	}
+}
+
+// WithScope runs the App in the given scope, the global scope by default
+func WithScope(scope Scope) AppOptsFn {
+	return func(opts AppOpts) AppOpts {
+		opts.scope = scope
+		return opts
+	}
+}
+
+// WithShutdownTimeout bounds the time stop hooks have to complete, 15 seconds by default
+func WithShutdownTimeout(timeout time.Duration) AppOptsFn {
+	return func(opts AppOpts) AppOpts {
+		opts.shutdownTimeout = timeout
+		return opts
+	}
+}
+
+// WithSignals changes the signals stopping the App, SIGINT and SIGTERM by default
+func WithSignals(signals ...os.Signal) AppOptsFn {
+	return func(opts AppOpts) AppOpts {
+		opts.signals = signals
+		return opts
+	}
+}
+
+// CreateApp creates an App
+//
+//	app := submodule.CreateApp(submodule.WithRoots(routes, mhttp.Server))
+//	if err := app.Run(context.Background()); err != nil {
+//	  log.Fatal(err)
+//	}
+func CreateApp(fns ...AppOptsFn) *App {
+	opts := AppOpts{
+		scope:           globalScope,
+		shutdownTimeout: 15 * time.Second,
+		signals:         []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+
+	for _, fn := range fns {
+		opts = fn(opts)
+	}
+
+	return &App{
+		scope:           opts.scope,
+		roots:           opts.roots,
+		shutdownTimeout: opts.shutdownTimeout,
+		signals:         opts.signals,
+	}
+}
+
+// Run resolves the roots with Warmup, then runs start hooks in dependency order.
+// It then waits for one of the signals or the context to be done, and runs stop hooks in reverse dependency order,
+// within the shutdown timeout.
+// A failing start hook stops the values started so far, including the one it belongs to when
+// some of its start hooks ran already, and its error is returned.
+// Stop hooks all run, their failures are returned joined
+func (a *App) Run(ctx context.Context) error {
+	if err := Warmup(ctx, a.scope, a.roots...); err != nil {
+		return err
+	}
+
+	groups := trace(a.scope).lifecycle()
+
+	for i, group := range groups {
+		started := false
+		for _, h := range group {
+			if h.start == nil {
+				continue
+			}
+
+			if err := h.start(ctx); err != nil {
+				err = fmt.Errorf("starting %s: %w", describeOwner(h.owner), err)
+				if started {
+					i++
+				}
+				return errors.Join(err, a.stop(groups[:i]))
+			}
+			started = true
+		}
+	}
+
+	wait, cancel := signal.NotifyContext(ctx, a.signals...)
+	defer cancel()
+	<-wait.Done()
+
+	return a.stop(groups)
+}
+
+// stop runs the stop hooks of the groups in reverse order, hooks still running at the shutdown timeout are abandoned
+func (a *App) stop(groups [][]hook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(groups) - 1; i >= 0; i-- {
+		for _, h := range groups[i] {
+			if h.stop == nil {
+				continue
+			}
+
+			done := make(chan error, 1)
+			go func() {
+				done <- h.stop(ctx)
+			}()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					errs = append(errs, fmt.Errorf("stopping %s: %w", describeOwner(h.owner), err))
+				}
+			case <-ctx.Done():
+				errs = append(errs, fmt.Errorf("stopping %s: %w", describeOwner(h.owner), ctx.Err()))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// describeOwner names the submodule which registered a hook
+func describeOwner(r Retrievable) string {
+	if r == nil {
+		return "unknown submodule"
+	}
+	return r.provides().String()
+}
@@ -0,0 +1,193 @@
+package submodule_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	lifeLogger struct{}
+	lifeDb     struct{}
+	lifeServer struct{}
+)
+
+type lifeRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *lifeRecorder) hooks(self submodule.Self, name string) {
+	self.OnStart(func(ctx context.Context) error {
+		r.record("start " + name)
+		return nil
+	})
+	self.OnStop(func(ctx context.Context) error {
+		r.record("stop " + name)
+		return nil
+	})
+}
+
+func (r *lifeRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestApp(t *testing.T) {
+	t.Run("hooks run in dependency order", func(t *testing.T) {
+		r := &lifeRecorder{}
+
+		logger := submodule.Make[*lifeLogger](func(self submodule.Self) *lifeLogger {
+			r.hooks(self, "logger")
+			return &lifeLogger{}
+		})
+
+		db := submodule.Make[*lifeDb](func(self submodule.Self, l *lifeLogger) *lifeDb {
+			r.hooks(self, "db")
+			return &lifeDb{}
+		}, logger)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		server := submodule.Make[*lifeServer](func(self submodule.Self, d *lifeDb) (*lifeServer, error) {
+			// registered before the logger is resolved, still started after it
+			r.hooks(self, "server")
+			self.OnStart(func(context.Context) error {
+				cancel()
+				return nil
+			})
+
+			_, e := logger.SafeResolveWith(self.Scope)
+			return &lifeServer{}, e
+		}, db)
+
+		app := submodule.CreateApp(
+			submodule.WithRoots(server),
+			submodule.WithScope(submodule.CreateScope()),
+		)
+		require.NoError(t, app.Run(ctx))
+
+		assert.Equal(t, []string{
+			"start logger", "start db", "start server",
+			"stop server", "stop db", "stop logger",
+		}, r.events)
+	})
+
+	t.Run("failing start stops what was started", func(t *testing.T) {
+		r := &lifeRecorder{}
+		errDb := errors.New("db is down")
+
+		logger := submodule.Make[*lifeLogger](func(self submodule.Self) *lifeLogger {
+			r.hooks(self, "logger")
+			return &lifeLogger{}
+		})
+
+		db := submodule.Make[*lifeDb](func(self submodule.Self, l *lifeLogger) *lifeDb {
+			self.OnStart(func(context.Context) error {
+				return errDb
+			})
+			r.hooks(self, "db")
+			return &lifeDb{}
+		}, logger)
+
+		app := submodule.CreateApp(
+			submodule.WithRoots(db),
+			submodule.WithScope(submodule.CreateScope()),
+		)
+
+		e := app.Run(context.Background())
+		assert.ErrorIs(t, e, errDb)
+		assert.ErrorContains(t, e, "starting *submodule_test.lifeDb")
+		assert.Equal(t, []string{"start logger", "stop logger"}, r.events)
+	})
+
+	t.Run("partially started values are stopped", func(t *testing.T) {
+		r := &lifeRecorder{}
+		errDb := errors.New("migrations failed")
+
+		db := submodule.Make[*lifeDb](func(self submodule.Self) *lifeDb {
+			r.hooks(self, "db")
+			self.OnStart(func(context.Context) error {
+				return errDb
+			})
+			return &lifeDb{}
+		})
+
+		e := submodule.CreateApp(
+			submodule.WithRoots(db),
+			submodule.WithScope(submodule.CreateScope()),
+		).Run(context.Background())
+
+		assert.ErrorIs(t, e, errDb)
+		assert.Equal(t, []string{"start db", "stop db"}, r.events)
+	})
+
+	t.Run("hooks of values built in a parent scope run", func(t *testing.T) {
+		r := &lifeRecorder{}
+
+		logger := submodule.Make[*lifeLogger](func(self submodule.Self) *lifeLogger {
+			r.hooks(self, "logger")
+			return &lifeLogger{}
+		}, submodule.WithPin(submodule.Shared))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		server := submodule.Make[*lifeServer](func(self submodule.Self, l *lifeLogger) *lifeServer {
+			r.hooks(self, "server")
+			self.OnStart(func(context.Context) error {
+				cancel()
+				return nil
+			})
+			return &lifeServer{}
+		}, logger)
+
+		app := submodule.CreateApp(
+			submodule.WithRoots(server),
+			submodule.WithScope(submodule.CreateScope(submodule.WithParent(submodule.CreateScope()))),
+		)
+		require.NoError(t, app.Run(ctx))
+
+		assert.Equal(t, []string{
+			"start logger", "start server",
+			"stop server", "stop logger",
+		}, r.events)
+	})
+
+	t.Run("stop hooks are bounded by the shutdown timeout", func(t *testing.T) {
+		r := &lifeRecorder{}
+
+		logger := submodule.Make[*lifeLogger](func(self submodule.Self) *lifeLogger {
+			r.hooks(self, "logger")
+			return &lifeLogger{}
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		server := submodule.Make[*lifeServer](func(self submodule.Self, l *lifeLogger) *lifeServer {
+			self.OnStart(func(context.Context) error {
+				cancel()
+				return nil
+			})
+			self.OnStop(func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			return &lifeServer{}
+		}, logger)
+
+		start := time.Now()
+		e := submodule.CreateApp(
+			submodule.WithRoots(server),
+			submodule.WithScope(submodule.CreateScope()),
+			submodule.WithShutdownTimeout(50*time.Millisecond),
+		).Run(ctx)
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.ErrorIs(t, e, context.DeadlineExceeded)
+		assert.ErrorContains(t, e, "stopping *submodule_test.lifeServer")
+	})
+}
@@ -1,6 +1,7 @@
 package mhttp
 
 import (
+	"crypto/tls"
 	"log/slog"
 	"net/http"
 	"time"
@@ -40,11 +41,27 @@ func AlterConfig(c func(*ServerConfig)) {
 	Server.Append(submodule.Value(mc))
 }
 
+// AlterConfigIn alters the server config for the given scope and its children only,
+// the global server config is left untouched
+func AlterConfigIn(scope submodule.Scope, c func(*ServerConfig)) {
+	mc := defaultServerConfig()
+	c(&mc)
+	Server.AppendWith(scope, submodule.Value(mc))
+}
+
 func Reset() {
 	Server.Reset()
 }
 
-var Server = submodule.MakeModifiable[*http.Server](func(self submodule.Self, config ServerConfig, logger *slog.Logger) *http.Server {
+// Server is the http server serving all resolved routes.
+// A *tls.Config is picked up when the application provides one, for example with
+// Server.Append(submodule.Value(&tls.Config{...}))
+var Server = submodule.MakeModifiable[*http.Server](func(
+	self submodule.Self,
+	config ServerConfig,
+	logger *slog.Logger,
+	tlsConfig submodule.Optional[*tls.Config],
+) *http.Server {
 	muxes := submodule.Find([]IntegrateWithHttpServer{}, self.Scope)
 	logger.Debug("server is running with", "config", config)
 	logger.Debug("found_routes %v", "muxes", muxes)
@@ -60,6 +77,8 @@ var Server = submodule.MakeModifiable[*http.Server](func(self submodule.Self, co
 		Addr:    config.Addr,
 	}
 
+	s.TLSConfig, _ = tlsConfig.Get()
+
 	s.SetKeepAlivesEnabled(config.KeepAlive)
 	s.ReadTimeout = config.ReadTimeout
 	s.WriteTimeout = config.WriteTimeout
@@ -1,9 +1,11 @@
 package mhttp_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 
@@ -54,7 +56,6 @@ func (s *MHTTPSuite) TearDownSubTest() {
 		s.server.Close()
 	}()
 	defer s.scope.Dispose()
-	defer mhttp.Reset()
 
 	wg.Wait()
 	r, e := http.Get(fmt.Sprintf("http://localhost:%d/hello", s.port))
@@ -71,7 +72,7 @@ func (s *MHTTPSuite) TearDownSubTest() {
 func (s *MHTTPSuite) TestCanChangePort() {
 	s.port = 28001
 
-	mhttp.AlterConfig(func(c *mhttp.ServerConfig) {
+	mhttp.AlterConfigIn(s.scope, func(c *mhttp.ServerConfig) {
 		c.Addr = fmt.Sprintf(":%d", s.port)
 	})
 }
@@ -79,8 +80,103 @@ func (s *MHTTPSuite) TestCanChangePort() {
 func (s *MHTTPSuite) TestCanStartServer() {
 }
 
+func TestValidate(t *testing.T) {
+	mhttp.AlterConfig(func(c *mhttp.ServerConfig) {
+		c.Addr = ":28002"
+	})
+	defer mhttp.Reset()
+
+	require.NoError(t, submodule.Validate(mhttp.Server))
+}
+
+func TestStrict(t *testing.T) {
+	mhttp.AlterConfig(func(c *mhttp.ServerConfig) {
+		c.Addr = ":28003"
+	})
+	defer mhttp.Reset()
+
+	submodule.SetStrict(true)
+	defer submodule.SetStrict(false)
+
+	server, e := mhttp.Server.SafeResolveWith(submodule.CreateScope())
+	require.NoError(t, e)
+	require.Equal(t, ":28003", server.Addr)
+}
+
+func TestRoutesOfParentScope(t *testing.T) {
+	app := submodule.CreateScope()
+	require.NoError(t, mhttp.ResolveRoutesIn(app, HelloRoute))
+
+	request := submodule.CreateScope(submodule.WithParent(app))
+	server, e := mhttp.Server.SafeResolveWith(request)
+	require.NoError(t, e)
+
+	w := httptest.NewRecorder()
+	server.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	require.Equal(t, 200, w.Code)
+	require.Equal(t, "hello", w.Body.String())
+}
+
 func TestMHTTP(t *testing.T) {
 	suite.Run(t, &MHTTPSuite{
 		port: 8080,
 	})
 }
+
+type greeter struct {
+	greeting string
+}
+
+type requestId struct {
+	id string
+}
+
+func TestHandle(t *testing.T) {
+	var built, disposed int
+
+	greeterMod := submodule.Make[*greeter](func() *greeter {
+		built++
+		return &greeter{greeting: "hello"}
+	})
+
+	requestIdMod := submodule.Make[*requestId](func(self submodule.Self, r *http.Request) *requestId {
+		self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+			disposed++
+			return nil
+		}))
+		return &requestId{id: r.URL.Query().Get("id")}
+	}, mhttp.Request)
+
+	handler := submodule.Make[http.HandlerFunc](func(ctx context.Context, g *greeter, id *requestId, w http.ResponseWriter) http.HandlerFunc {
+		return func(_ http.ResponseWriter, r *http.Request) {
+			require.Equal(t, r.Context(), ctx)
+
+			// code reached with the request context resolves in the request scope
+			rid, e := requestIdMod.SafeResolveFrom(r.Context())
+			require.NoError(t, e)
+			require.Same(t, id, rid)
+			w.Write([]byte(g.greeting + " " + id.id))
+		}
+	}, greeterMod, requestIdMod, mhttp.ResponseWriter)
+
+	scope := submodule.CreateScope()
+	greeterMod.ResolveWith(scope)
+	route, e := mhttp.Handle("/greet", handler).SafeResolveWith(scope)
+	require.NoError(t, e)
+
+	mux := http.NewServeMux()
+	route.AdaptToHTTPHandler(mux)
+
+	for _, id := range []string{"1", "2"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/greet?id="+id, nil))
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, "hello "+id, w.Body.String())
+	}
+
+	require.Equal(t, 1, built)
+	require.Equal(t, 2, disposed)
+
+	_, e = mhttp.Request.SafeResolveWith(submodule.CreateScope())
+	require.ErrorIs(t, e, mhttp.ErrOutsideRequest)
+}
@@ -0,0 +1,81 @@
+package mhttp
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+// ErrOutsideRequest is reported when resolving Request or ResponseWriter outside of a request scope
+var ErrOutsideRequest = errors.New("only available in a request scope, see mhttp.Handle")
+
+// Request is the request being served, available to handlers declared with Handle and their dependencies.
+// A context.Context parameter receives the request context
+var Request = submodule.Make[*http.Request](func() (*http.Request, error) {
+	return nil, ErrOutsideRequest
+})
+
+// ResponseWriter is the writer of the response being served, available to handlers declared with Handle and their dependencies
+var ResponseWriter = submodule.Make[http.ResponseWriter](func() (http.ResponseWriter, error) {
+	return nil, ErrOutsideRequest
+})
+
+// ScopedRoute serves a pattern with a handler built for each request, see Handle
+type ScopedRoute struct {
+	pattern string
+	scope   submodule.Scope
+	handler func(submodule.Scope, *http.Request) (http.Handler, error)
+	logger  *slog.Logger
+}
+
+// Handle declares a route whose handler is resolved in a scope created for each request.
+// The request scope is a child of the scope the route is resolved in, it is seeded with Request and ResponseWriter
+// and disposed once the response is written. It is attached to the request context as well, see submodule.ContextWithScope.
+// Values resolved in the parent scope, or pinned with submodule.WithPin(submodule.Shared), are shared by every request.
+// Other values are built in the request scope
+//
+//	var UserHandler = submodule.Make[http.HandlerFunc](func(r *http.Request, users *UserService) http.HandlerFunc {
+//	  return func(w http.ResponseWriter, _ *http.Request) { ... }
+//	}, mhttp.Request, UserServiceMod)
+//
+//	var UserRoute = mhttp.Handle("GET /users/{id}", UserHandler)
+func Handle[T http.Handler](pattern string, handler submodule.Submodule[T]) submodule.Submodule[*ScopedRoute] {
+	return submodule.Make[*ScopedRoute](func(self submodule.Self, logger *slog.Logger) *ScopedRoute {
+		return &ScopedRoute{
+			pattern: pattern,
+			scope:   self.Scope,
+			handler: func(scope submodule.Scope, r *http.Request) (http.Handler, error) {
+				return handler.SafeResolveContext(r.Context(), scope)
+			},
+			logger: logger,
+		}
+	}, defaultHttpLogger)
+}
+
+func (s *ScopedRoute) AdaptToHTTPHandler(m *http.ServeMux) {
+	m.HandleFunc(s.pattern, s.ServeHTTP)
+}
+
+func (s *ScopedRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scope := submodule.CreateScope(submodule.WithParent(s.scope))
+	defer func() {
+		if e := scope.Dispose(); e != nil {
+			s.logger.Error("failed to dispose request scope", "pattern", s.pattern, slog.Any("error", e))
+		}
+	}()
+
+	r = r.WithContext(submodule.ContextWithScope(r.Context(), scope))
+	Request.ResolveToWith(scope, r)
+	ResponseWriter.ResolveToWith(scope, w)
+
+	h, e := s.handler(scope, r)
+	if e != nil {
+		s.logger.Error("failed to resolve handler", "pattern", s.pattern, slog.Any("error", e))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h.ServeHTTP(w, r)
+}
@@ -35,7 +35,7 @@ func Reset() {
 
 var defaultRedisConfigMod = submodule.Value(defaultRedisConfig)
 
-var Client = submodule.MakeModifiable[*RedisClient](func(self submodule.Self, config RedisConfig, logger *slog.Logger) (*RedisClient, error) {
+var Client = submodule.MakeModifiable[*RedisClient](func(self submodule.Self, ctx context.Context, config RedisConfig, logger *slog.Logger) (*RedisClient, error) {
 	logger.Debug("parsing config", "config object", config)
 	opts, e := redis.ParseURL(config.Url)
 	if e != nil {
@@ -46,7 +46,7 @@ var Client = submodule.MakeModifiable[*RedisClient](func(self submodule.Self, co
 	client := redis.NewClient(opts)
 
 	logger.Debug("trying to connect to redis", "url", config.Url)
-	e = client.Ping(context.TODO()).Err()
+	e = client.Ping(ctx).Err()
 
 	if e != nil {
 		logger.Error("failed to ping redis server, is it connected?", slog.Any("error", e), "url", config.Url)
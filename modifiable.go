@@ -1,6 +1,10 @@
 package submodule
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+	"slices"
+)
 
 // ModifiableSubmodule is a submodule that can be modified.
 // Overriding will be done by using the Append method mechanism, the default value will always be resolved last
@@ -8,16 +12,28 @@ import "reflect"
 type ModifiableSubmodule[T any] interface {
 	Submodule[T]
 	Append(submodule ...Retrievable)
+	// Same as Append, but only for resolutions in the scope and its children
+	AppendWith(scope Scope, submodule ...Retrievable)
 	Reset()
 }
 
 type modifiableSubmodule[T any] struct {
-	submodule Submodule[T]
-	modifiers []Retrievable
+	submodule    Submodule[T]
+	modifiers    []Retrievable
+	input        any
+	dependencies []Retrievable
+	slots        []slot
+	opts         SubmoduleOpts
+	src          source
+	// set by Substitute, the submodule is built by the substitute alone
+	substituted bool
 }
 
+// Substitute replaces the whole submodule, modifiers no longer apply to it
 func (m *modifiableSubmodule[T]) Substitute(other Submodule[T]) {
 	m.submodule.Substitute(other)
+	m.submodule.(*submodule[T]).outer = nil
+	m.substituted = true
 }
 
 // Resolve implements ModifiableSubmodule.
@@ -50,6 +66,21 @@ func (m *modifiableSubmodule[T]) SafeResolveWith(s Scope) (T, error) {
 	return m.submodule.SafeResolveWith(s)
 }
 
+// SafeResolveContext implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) SafeResolveContext(ctx context.Context, s Scope) (T, error) {
+	return m.submodule.SafeResolveContext(ctx, s)
+}
+
+// SafeResolveFrom implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) SafeResolveFrom(ctx context.Context) (T, error) {
+	return m.submodule.SafeResolveFrom(ctx)
+}
+
+// ResolveFrom implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) ResolveFrom(ctx context.Context) T {
+	return m.submodule.ResolveFrom(ctx)
+}
+
 // canResolve implements ModifiableSubmodule.
 func (m *modifiableSubmodule[T]) canResolve(t reflect.Type) bool {
 	return m.submodule.canResolve(t)
@@ -60,6 +91,55 @@ func (m *modifiableSubmodule[T]) retrieve(s Scope) (any, error) {
 	return m.submodule.retrieve(s)
 }
 
+// provides implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) provides() reflect.Type {
+	return m.submodule.provides()
+}
+
+// options implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) options() SubmoduleOpts {
+	return m.submodule.options()
+}
+
+// source implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) source() source {
+	if m.substituted {
+		return m.submodule.source()
+	}
+	return m.src
+}
+
+// key implements keyed, values are cached under the submodule built by the modifiable submodule
+func (m *modifiableSubmodule[T]) key() Retrievable {
+	return m.submodule
+}
+
+// links implements ModifiableSubmodule.
+func (m *modifiableSubmodule[T]) links() []link {
+	if m.substituted {
+		return m.submodule.links()
+	}
+	return linksOf(m.slots, tiers{m.modifiers, m.dependencies}, m.opts.strictly())
+}
+
+// rankedSubmodule is the submodule built with the given factory and dependencies by precedence.
+// Unlike Make it never panics, problems are reported when resolving it
+func rankedSubmodule[T any](input any, opts SubmoduleOpts, ranked tiers) *submodule[T] {
+	var dependencies []Retrievable
+	for _, t := range ranked {
+		dependencies = append(dependencies, t...)
+	}
+
+	return &submodule[T]{
+		input:        input,
+		provideType:  reflect.TypeOf(input).Out(0),
+		dependencies: dependencies,
+		slots:        slotsOf(reflect.TypeOf(input), opts.params),
+		opts:         opts,
+		ranked:       ranked,
+	}
+}
+
 func (m *modifiableSubmodule[T]) Append(submodule ...Retrievable) {
 	if len(submodule) == 0 {
 		return
@@ -73,17 +153,43 @@ func (m *modifiableSubmodule[T]) Reset() {
 
 // Modifiable constructor. Parameters are the same as Make
 func MakeModifiable[T any](fn any, dependencies ...Retrievable) ModifiableSubmodule[T] {
+	if err := validateInput(fn, true); err != nil {
+		panic(err)
+	}
+
+	opts, deps := splitOpts(dependencies)
 	xs := &modifiableSubmodule[T]{
-		modifiers: []Retrievable{},
+		modifiers:    []Retrievable{},
+		input:        fn,
+		dependencies: deps,
+		slots:        slotsOf(reflect.TypeOf(fn), opts.params),
+		opts:         opts,
+		src:          sourceOf(fn),
 	}
 
 	xs.submodule = Make[T](func(self Self) (t T, e error) {
-		var modifiedDependencies []Retrievable
-		modifiedDependencies = append(modifiedDependencies, xs.modifiers...)
-		modifiedDependencies = append(modifiedDependencies, dependencies...)
-
-		return Make[T](fn, modifiedDependencies...).SafeResolveWith(self.Scope)
+		f := trace(self.Scope)
+		o := f.scope.override(xs.submodule)
+
+		input, opts, dependencies := xs.input, xs.opts, xs.dependencies
+		if b, ok := o.base.(*submodule[T]); ok {
+			input, opts.params, dependencies = b.input, b.opts.params, b.dependencies
+		}
+
+		// modifiers appended to the scope take precedence over the global ones, both over the defaults
+		ranked := tiers{o.modifiers, slices.Clip(xs.modifiers), dependencies}
+		v, _ := rankedSubmodule[T](input, opts, ranked).build(f)
+		if v.e.IsValid() {
+			return t, v.e.Interface().(error)
+		}
+
+		if v.value.IsZero() {
+			return t, nil
+		}
+
+		return v.value.Interface().(T), nil
 	}, dependencies...)
+	xs.submodule.(*submodule[T]).outer = xs
 
 	return xs
 }
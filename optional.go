@@ -0,0 +1,57 @@
+package submodule
+
+import "reflect"
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+// implemented by every Optional[T], to fill them without knowing T
+type optional interface {
+	elem() reflect.Type
+	with(v reflect.Value) reflect.Value
+}
+
+// Optional is a dependency which may be absent. Declare Optional[T] instead of T as a factory parameter
+// or an In field, it is present when one of the dependencies provides T and absent otherwise.
+// A failing dependency is still reported as an error.
+//
+//	var Server = submodule.Make[*http.Server](func(tls submodule.Optional[*tls.Config]) *http.Server {
+//	  s := &http.Server{}
+//	  s.TLSConfig, _ = tls.Get()
+//	  return s
+//	}, dependencies...)
+//
+// Fields of an In struct can use the `optional:"true"` tag instead, they keep their zero value when absent
+type Optional[T any] struct {
+	value   T
+	present bool
+}
+
+// Get returns the value and whether it is present
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.present
+}
+
+// Present tells whether a dependency provided the value
+func (o Optional[T]) Present() bool {
+	return o.present
+}
+
+// OrElse returns the value when present, fallback otherwise
+func (o Optional[T]) OrElse(fallback T) T {
+	if o.present {
+		return o.value
+	}
+	return fallback
+}
+
+func (o Optional[T]) elem() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (o Optional[T]) with(v reflect.Value) reflect.Value {
+	o.present = true
+	if v.IsValid() {
+		o.value = v.Interface().(T)
+	}
+	return reflect.ValueOf(o)
+}
@@ -0,0 +1,79 @@
+package submodule_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	optionalTLS     struct{}
+	optionalMetrics struct{}
+)
+
+func TestOptional(t *testing.T) {
+	tls := submodule.Value(&optionalTLS{})
+
+	t.Run("optional parameter", func(t *testing.T) {
+		withTLS := submodule.Make[bool](func(tls submodule.Optional[*optionalTLS]) bool {
+			return tls.Present()
+		}, tls)
+
+		withoutTLS := submodule.Make[bool](func(tls submodule.Optional[*optionalTLS]) bool {
+			_, ok := tls.Get()
+			return ok
+		})
+
+		scope := submodule.CreateScope()
+		assert.True(t, withTLS.ResolveWith(scope))
+		assert.False(t, withoutTLS.ResolveWith(scope))
+	})
+
+	t.Run("optional fields", func(t *testing.T) {
+		server := submodule.Make[[]bool](func(p struct {
+			submodule.In
+			TLS     submodule.Optional[*optionalTLS]
+			Metrics *optionalMetrics `optional:"true"`
+		}) []bool {
+			return []bool{p.TLS.Present(), p.Metrics != nil}
+		}, tls)
+
+		assert.Equal(t, []bool{true, false}, server.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("absent value falls back", func(t *testing.T) {
+		port := submodule.Make[int](func(p submodule.Optional[int]) int {
+			return p.OrElse(8080)
+		})
+
+		assert.Equal(t, 8080, port.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("named optional", func(t *testing.T) {
+		replica := submodule.Value("replica")
+		dsn := submodule.Make[string](func(p struct {
+			submodule.In
+			Replica submodule.Optional[string] `name:"replica"`
+		}) string {
+			return p.Replica.OrElse("primary")
+		}, replica)
+
+		assert.Equal(t, "primary", dsn.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("failing dependency is still an error", func(t *testing.T) {
+		failing := submodule.Make[*optionalMetrics](func() (*optionalMetrics, error) {
+			return nil, errors.New("registry unavailable")
+		})
+
+		server := submodule.Make[bool](func(m submodule.Optional[*optionalMetrics]) bool {
+			return m.Present()
+		}, failing)
+
+		_, e := server.SafeResolveWith(submodule.CreateScope())
+		require.ErrorContains(t, e, "registry unavailable")
+	})
+}
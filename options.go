@@ -0,0 +1,209 @@
+package submodule
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// SubmoduleOpts tunes how a submodule is resolved.
+// Options are given to Make, MakeModifiable and Resolve along with the dependencies
+//
+//	var DbMod = submodule.Make[*sql.DB](OpenDB, ConfigMod, submodule.WithTimeout(5*time.Second))
+type SubmoduleOpts struct {
+	timeout  time.Duration
+	lifetime Lifetime
+	name     string
+	params   []string
+	strict   bool
+	pin      Pin
+}
+
+type SubmoduleOptsFn func(opts SubmoduleOpts) SubmoduleOpts
+
+// Lifetime tells how long a value provided by a submodule is kept
+type Lifetime int
+
+const (
+	// one value per scope, the default
+	Singleton Lifetime = iota
+	// a new value on every resolution, never cached in the scope
+	Transient
+	// one value in the outermost scope, shared by every scope inheriting from it
+	RootSingleton
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case RootSingleton:
+		return "root singleton"
+	default:
+		return "unknown lifetime"
+	}
+}
+
+// Pin tells which scope of a parent/child chain a submodule is built in.
+// Whatever the pin, a submodule is built once per scope and values are never copied from a scope to another
+type Pin int
+
+const (
+	// a value found in the scope or one of its parents is used, otherwise it is built in the resolving scope. The default
+	Nearest Pin = iota
+	// a value found in the scope or one of its parents is used, otherwise it is built in the parent scope
+	// and shared by all its children
+	Shared
+	// parents are never consulted, each scope builds its own value
+	Isolated
+)
+
+func (p Pin) String() string {
+	switch p {
+	case Nearest:
+		return "nearest"
+	case Shared:
+		return "shared"
+	case Isolated:
+		return "isolated"
+	default:
+		return "unknown pin"
+	}
+}
+
+// WithPin changes which scope of a parent/child chain a submodule is built in, Nearest by default
+//
+//	var Pool = submodule.Make[*sql.DB](OpenDB, ConfigMod, submodule.WithPin(submodule.Shared))
+func WithPin(pin Pin) SubmoduleOptsFn {
+	return func(opts SubmoduleOpts) SubmoduleOpts {
+		opts.pin = pin
+		return opts
+	}
+}
+
+// WithLifetime changes how long the values of a submodule are kept, Singleton by default
+//
+//	var RequestBuilder = submodule.Make[*Builder](NewBuilder, submodule.WithLifetime(submodule.Transient))
+func WithLifetime(lifetime Lifetime) SubmoduleOptsFn {
+	return func(opts SubmoduleOpts) SubmoduleOpts {
+		opts.lifetime = lifetime
+		return opts
+	}
+}
+
+// WithTimeout bounds the time a submodule takes to be built, resolving its dependencies included.
+// Past the timeout, resolution fails with a *TimeoutError and the factory context is done
+func WithTimeout(timeout time.Duration) SubmoduleOptsFn {
+	return func(opts SubmoduleOpts) SubmoduleOpts {
+		opts.timeout = timeout
+		return opts
+	}
+}
+
+// WithName names a submodule, so factories depending on several values of the same type can tell them apart
+//
+//	var Primary = submodule.Make[*sql.DB](OpenPrimary, ConfigMod, submodule.WithName("primary"))
+//	var Replica = submodule.Make[*sql.DB](OpenReplica, ConfigMod, submodule.WithName("replica"))
+func WithName(name string) SubmoduleOptsFn {
+	return func(opts SubmoduleOpts) SubmoduleOpts {
+		opts.name = name
+		return opts
+	}
+}
+
+// WithParamNames asks for named dependencies, by position of the factory parameters.
+// An empty name accepts any dependency providing the parameter type.
+// Fields of an In struct or Resolve[T] are named with the `name` tag instead
+//
+//	var Repo = submodule.Make[*Repo](func(write *sql.DB, read *sql.DB) *Repo {
+//	  return &Repo{write, read}
+//	}, Primary, Replica, submodule.WithParamNames("primary", "replica"))
+func WithParamNames(names ...string) SubmoduleOptsFn {
+	return func(opts SubmoduleOpts) SubmoduleOpts {
+		opts.params = names
+		return opts
+	}
+}
+
+// WithStrict fails the resolution of a submodule when more than one dependency can provide one of its values,
+// instead of picking the first one. Dependencies providing exactly the requested type still win over those merely assignable to it.
+// Strict mode can be turned on for every submodule with SetStrict, or the SM_STRICT environment variable
+func WithStrict() SubmoduleOptsFn {
+	return func(opts SubmoduleOpts) SubmoduleOpts {
+		opts.strict = true
+		return opts
+	}
+}
+
+var strictMode atomic.Bool
+
+func init() {
+	if ok, e := strconv.ParseBool(os.Getenv("SM_STRICT")); ok && e == nil {
+		strictMode.Store(true)
+	}
+}
+
+// SetStrict turns strict mode on or off for every submodule, see WithStrict.
+// Submodules made before strict mode is turned on are only checked when resolved
+func SetStrict(strict bool) {
+	strictMode.Store(strict)
+}
+
+// strictly tells whether the options, or the global strict mode, ask for strictness
+func (opts SubmoduleOpts) strictly() bool {
+	return opts.strict || strictMode.Load()
+}
+
+// isStrict tells whether the submodule being built in the scope is strict
+func isStrict(scope Scope) bool {
+	if strictMode.Load() {
+		return true
+	}
+
+	path := trace(scope).path
+	return len(path) > 0 && path[len(path)-1].r.options().strict
+}
+
+// options travel along with dependencies, but never resolve anything
+func (fn SubmoduleOptsFn) retrieve(Scope) (any, error) {
+	return nil, fmt.Errorf("submodule options cannot be retrieved")
+}
+
+func (fn SubmoduleOptsFn) canResolve(reflect.Type) bool {
+	return false
+}
+
+func (fn SubmoduleOptsFn) provides() reflect.Type {
+	return nil
+}
+
+func (fn SubmoduleOptsFn) links() []link {
+	return nil
+}
+
+func (fn SubmoduleOptsFn) options() SubmoduleOpts {
+	return SubmoduleOpts{}
+}
+
+func (fn SubmoduleOptsFn) source() source {
+	return source{}
+}
+
+// splitOpts separates options from the actual dependencies
+func splitOpts(dependencies []Retrievable) (SubmoduleOpts, []Retrievable) {
+	var opts SubmoduleOpts
+	var rs []Retrievable
+	for _, d := range dependencies {
+		if fn, ok := d.(SubmoduleOptsFn); ok {
+			opts = fn(opts)
+			continue
+		}
+		rs = append(rs, d)
+	}
+	return opts, rs
+}
@@ -0,0 +1,151 @@
+package submodule
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// override changes how a submodule is built in a scope and its children, its definition is left untouched
+type override struct {
+	// replaces the submodule, see SubstituteWith
+	with Retrievable
+	// replaces the factory of a modifiable submodule, its modifiers still apply
+	base Retrievable
+	// modifiers of a modifiable submodule, see AppendWith
+	modifiers []Retrievable
+}
+
+// addOverride registers an override of g in the scope
+func (s *scope) addOverride(g Retrievable, fn func(o override) override) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.overrides == nil {
+		s.overrides = make(map[Retrievable]override)
+	}
+	s.overrides[g] = fn(s.overrides[g])
+}
+
+// overriding tells whether g, or one of its dependencies, is overridden in the scope itself, parents aside.
+// Values of g built by the parents are not the ones asked for in the scope
+func (s *scope) overriding(g Retrievable) bool {
+	s.mu.Lock()
+	overridden := make(map[Retrievable]bool, len(s.overrides))
+	for k := range s.overrides {
+		overridden[k] = true
+	}
+	s.mu.Unlock()
+
+	if len(overridden) == 0 {
+		return false
+	}
+	return dependsOn(g, overridden, map[Retrievable]bool{})
+}
+
+// dependsOn tells whether r or one of its dependencies is in the set
+func dependsOn(r Retrievable, set map[Retrievable]bool, seen map[Retrievable]bool) bool {
+	if seen[r] {
+		return false
+	}
+	seen[r] = true
+
+	if set[keyOf(r)] {
+		return true
+	}
+	for _, l := range r.links() {
+		if l.target != nil && dependsOn(l.target, set, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyed is implemented by submodules whose values are cached under another submodule
+type keyed interface {
+	key() Retrievable
+}
+
+// keyOf is the submodule the values of r are cached under
+func keyOf(r Retrievable) Retrievable {
+	if k, ok := r.(keyed); ok {
+		return k.key()
+	}
+	return r
+}
+
+// override merges the overrides of g along the chain of the scope.
+// The nearest substitutes win, modifiers of the nearest scopes come first
+func (s *scope) override(g Retrievable) override {
+	var o override
+	for _, c := range s.chain() {
+		c.mu.Lock()
+		x, ok := c.overrides[g]
+		c.mu.Unlock()
+
+		if !ok {
+			continue
+		}
+		if o.with == nil {
+			o.with = x.with
+		}
+		if o.base == nil {
+			o.base = x.base
+		}
+		o.modifiers = append(o.modifiers, x.modifiers...)
+	}
+	return o
+}
+
+// SubstituteWith substitutes the submodule with another one in the scope and its children only.
+// The substitute builds the value, which is cached according to the lifetime and pin of the submodule.
+// Values resolved before the substitution are kept
+//
+//	scope := submodule.CreateScope()
+//	DbMod.SubstituteWith(scope, submodule.Make[*sql.DB](OpenTestDB))
+func (s *submodule[T]) SubstituteWith(as Scope, other Submodule[T]) {
+	o, ok := other.(*submodule[T])
+	if !ok {
+		panic(fmt.Sprintf("only submodule can be substituted, received: %v", reflect.TypeOf(other)))
+	}
+
+	scopeOf(as).addOverride(s, func(x override) override {
+		x.with = o
+		return x
+	})
+}
+
+// SubstituteWith implements ModifiableSubmodule, modifiers keep taking precedence over the substitute dependencies.
+// Unlike Substitute, which replaces the whole submodule, the substitute only stands for the factory and its defaults
+func (m *modifiableSubmodule[T]) SubstituteWith(as Scope, other Submodule[T]) {
+	o, ok := other.(*submodule[T])
+	if !ok {
+		panic(fmt.Sprintf("only submodule can be substituted, received: %v", reflect.TypeOf(other)))
+	}
+
+	scopeOf(as).addOverride(m.submodule, func(x override) override {
+		x.base = o
+		return x
+	})
+}
+
+// AppendWith appends modifiers in the scope and its children only.
+// They take precedence over the modifiers appended to parent scopes, and globally with Append
+func (m *modifiableSubmodule[T]) AppendWith(as Scope, submodule ...Retrievable) {
+	if len(submodule) == 0 {
+		return
+	}
+
+	scopeOf(as).addOverride(m.submodule, func(x override) override {
+		x.modifiers = append(slices.Clip(x.modifiers), submodule...)
+		return x
+	})
+}
+
+// scopeOf is the scope behind as, the global scope when nil
+func scopeOf(as Scope) *scope {
+	if as == nil {
+		as = globalScope
+	}
+	return trace(as).scope
+}
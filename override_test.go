@@ -0,0 +1,101 @@
+package submodule_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	overrideConfig struct {
+		addr string
+	}
+	overrideServer struct {
+		config overrideConfig
+	}
+)
+
+func TestOverride(t *testing.T) {
+	t.Run("substitute stays in the scope and its children", func(t *testing.T) {
+		t.Parallel()
+		config := submodule.Make[overrideConfig](func() overrideConfig {
+			return overrideConfig{addr: ":8080"}
+		})
+		server := submodule.Make[*overrideServer](func(c overrideConfig) *overrideServer {
+			return &overrideServer{config: c}
+		}, config)
+
+		app := submodule.CreateScope()
+		assert.Equal(t, ":8080", server.ResolveWith(app).config.addr)
+
+		test := submodule.CreateScope(submodule.WithParent(app))
+		config.SubstituteWith(test, submodule.Make[overrideConfig](func() overrideConfig {
+			return overrideConfig{addr: ":9090"}
+		}))
+
+		// the parent value was built without the substitute, it is not reused
+		request := submodule.CreateScope(submodule.WithParent(test))
+		assert.Equal(t, ":9090", server.ResolveWith(request).config.addr)
+		assert.Equal(t, ":9090", config.ResolveWith(test).addr)
+
+		assert.Equal(t, ":8080", config.ResolveWith(app).addr)
+		assert.Equal(t, ":8080", config.ResolveWith(submodule.CreateScope()).addr)
+	})
+
+	t.Run("appended modifiers stay in the scope and its children", func(t *testing.T) {
+		t.Parallel()
+		server := submodule.MakeModifiable[*overrideServer](func(c overrideConfig) *overrideServer {
+			return &overrideServer{config: c}
+		}, submodule.Value(overrideConfig{addr: ":8080"}))
+		server.Append(submodule.Value(overrideConfig{addr: ":7070"}))
+
+		addr := submodule.Make[string](func(s *overrideServer) string {
+			return s.config.addr
+		}, server)
+
+		app := submodule.CreateScope()
+		assert.Equal(t, ":7070", addr.ResolveWith(app))
+
+		test := submodule.CreateScope(submodule.WithParent(app))
+		server.AppendWith(test, submodule.Value(overrideConfig{addr: ":9090"}))
+
+		request := submodule.CreateScope(submodule.WithParent(test))
+		assert.Equal(t, ":9090", server.ResolveWith(request).config.addr)
+		assert.Equal(t, ":9090", addr.ResolveWith(request))
+		assert.Equal(t, ":7070", addr.ResolveWith(app))
+		assert.Equal(t, ":7070", server.ResolveWith(submodule.CreateScope()).config.addr)
+	})
+
+	t.Run("modifiers apply to a substitute", func(t *testing.T) {
+		t.Parallel()
+		server := submodule.MakeModifiable[*overrideServer](func(c overrideConfig) *overrideServer {
+			return &overrideServer{config: c}
+		}, submodule.Value(overrideConfig{addr: ":8080"}))
+
+		test := submodule.CreateScope()
+		server.SubstituteWith(test, submodule.Make[*overrideServer](func(c overrideConfig) *overrideServer {
+			return &overrideServer{config: overrideConfig{addr: "substitute" + c.addr}}
+		}, submodule.Value(overrideConfig{addr: ":8080"})))
+		server.AppendWith(test, submodule.Value(overrideConfig{addr: ":9090"}))
+
+		assert.Equal(t, "substitute:9090", server.ResolveWith(test).config.addr)
+		assert.Equal(t, ":8080", server.ResolveWith(submodule.CreateScope()).config.addr)
+	})
+
+	t.Run("shared submodules are not built above the overriding scope", func(t *testing.T) {
+		t.Parallel()
+		config := submodule.Make[overrideConfig](func() overrideConfig {
+			return overrideConfig{addr: ":8080"}
+		}, submodule.WithPin(submodule.Shared))
+
+		app := submodule.CreateScope()
+		test := submodule.CreateScope(submodule.WithParent(app))
+		config.SubstituteWith(test, submodule.Make[overrideConfig](func() overrideConfig {
+			return overrideConfig{addr: ":9090"}
+		}))
+
+		assert.Equal(t, ":9090", config.ResolveWith(test).addr)
+		assert.Equal(t, ":8080", config.ResolveWith(app).addr)
+	})
+}
@@ -1,8 +1,13 @@
 package submodule
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 )
 
 func isInEmbedded(t reflect.Type) bool {
@@ -23,14 +28,206 @@ func isSelf(t reflect.Type) bool {
 	return t.AssignableTo(selfType)
 }
 
+func isContext(t reflect.Type) bool {
+	return t == contextType
+}
+
+// slot is a value a factory needs, either a parameter or a field of an In struct
+type slot struct {
+	index int
+	field string
+	typ   reflect.Type
+	// name of the dependency the slot asks for, any dependency providing the type otherwise
+	name string
+	// an optional slot is filled with the zero value when no dependency can fill it
+	optional bool
+	// a lazy slot is filled with a handle, its dependency is resolved on demand
+	lazy bool
+	// Optional[T] or Lazy[T] type declared for the slot, typ is then T
+	holder reflect.Type
+	// dependency filling the slot no matter its type, otherwise it is matched against dependencies
+	dep Retrievable
+	// an unexported field, which cannot be filled
+	unexported bool
+}
+
+// newSlot unwraps Optional[T] and Lazy[T] slots, which look for T instead
+func newSlot(s slot) slot {
+	switch {
+	case s.typ.Implements(optionalType):
+		s.holder = s.typ
+		s.typ = reflect.Zero(s.typ).Interface().(optional).elem()
+		s.optional = true
+	case s.typ.Implements(lazyType):
+		s.holder = s.typ
+		s.typ = reflect.Zero(s.typ).Interface().(lazy).elem()
+		s.lazy = true
+	}
+	return s
+}
+
+// zero is the value of an optional slot no dependency can fill
+func (s slot) zero() reflect.Value {
+	if s.holder != nil {
+		return reflect.Zero(s.holder)
+	}
+	return reflect.Zero(s.typ)
+}
+
+// tiers group dependencies by precedence, a slot is filled from the first tier able to.
+// Modifiers of a modifiable submodule come before its default dependencies
+type tiers [][]Retrievable
+
+// candidates lists the dependencies able to fill the slot (and providing the name, if any), within the first tier having any
+func (s slot) candidates(t tiers, strict bool) []Retrievable {
+	if s.dep != nil {
+		return []Retrievable{s.dep}
+	}
+
+	for _, dependencies := range t {
+		if c := s.within(dependencies, strict); len(c) > 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// within lists the given dependencies able to fill the slot, in order.
+// In strict mode, dependencies providing exactly the slot type are preferred over those merely assignable to it
+func (s slot) within(dependencies []Retrievable, strict bool) []Retrievable {
+	var exact, assignable []Retrievable
+	for _, d := range dependencies {
+		if !d.canResolve(s.typ) || (s.name != "" && d.options().name != s.name) {
+			continue
+		}
+
+		switch {
+		case strict && d.provides() == s.typ:
+			if !slices.Contains(exact, d) {
+				exact = append(exact, d)
+			}
+		case !slices.Contains(assignable, d):
+			assignable = append(assignable, d)
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+	return assignable
+}
+
+// ambiguity describes a slot more than one dependency can fill
+func (s slot) ambiguity(candidates []Retrievable) error {
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = describe(c)
+	}
+	return fmt.Errorf("%s can be provided by %s", s.String(), strings.Join(names, ", "))
+}
+
+func (s slot) String() string {
+	if s.name != "" {
+		return fmt.Sprintf("%s named %s", s.typ.String(), s.name)
+	}
+	return s.typ.String()
+}
+
+// link is a slot together with the dependency that will fill it, nil when none can
+type link struct {
+	slot
+	target Retrievable
+	// every dependency able to fill the slot, target is the first one
+	candidates []Retrievable
+}
+
+// slotsOf lists the values a factory signature will be injected with,
+// names are the names asked for positional parameters
+func slotsOf(fnType reflect.Type, names []string) []slot {
+	var slots []slot
+	for i := 0; i < fnType.NumIn(); i++ {
+		pt := fnType.In(i)
+		if isSelf(pt) || isContext(pt) {
+			continue
+		}
+
+		if isInEmbedded(pt) {
+			slots = append(slots, fieldSlotsOf(i, pt)...)
+			continue
+		}
+
+		slots = append(slots, paramSlot(i, pt, names))
+	}
+	return slots
+}
+
+func paramSlot(index int, t reflect.Type, names []string) slot {
+	return newSlot(slot{index: index, typ: t, name: nameOf(names, index)})
+}
+
+func nameOf(names []string, index int) string {
+	if index < len(names) {
+		return names[index]
+	}
+	return ""
+}
+
+// fieldSlot is the slot of an In struct or Resolve[T] field, named with the `name` tag
+// and made optional with the `optional:"true"` tag
+func fieldSlot(index int, f reflect.StructField) slot {
+	optional, _ := strconv.ParseBool(f.Tag.Get("optional"))
+	return newSlot(slot{
+		index:      index,
+		field:      f.Name,
+		typ:        f.Type,
+		name:       f.Tag.Get("name"),
+		optional:   optional,
+		unexported: !f.IsExported(),
+	})
+}
+
+// fieldSlotsOf lists the fields resolveEmbedded will fill
+func fieldSlotsOf(index int, t reflect.Type) []slot {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var slots []slot
+	for fi := 0; fi < t.NumField(); fi++ {
+		f := t.Field(fi)
+		if f.Anonymous {
+			continue
+		}
+
+		slots = append(slots, fieldSlot(index, f))
+	}
+	return slots
+}
+
+func linksOf(slots []slot, dependencies tiers, strict bool) []link {
+	links := make([]link, len(slots))
+	for i, s := range slots {
+		links[i] = link{slot: s, candidates: s.candidates(dependencies, strict)}
+		if len(links[i].candidates) > 0 {
+			links[i].target = links[i].candidates[0]
+		}
+	}
+	return links
+}
+
 func resolveEmbedded(as Scope, t reflect.Type, v reflect.Value, dependencies []Retrievable) (reflect.Value, error) {
+	return resolveFields(as, t, v, tiers{dependencies})
+}
+
+// resolveFields fills the fields of an In struct, or of the struct given to Resolve, with dependencies by precedence
+func resolveFields(as Scope, t reflect.Type, v reflect.Value, dependencies tiers) (reflect.Value, error) {
 	var pt reflect.Type
 	var pv reflect.Value
 
-	store := GetStore()
-	if as != nil {
-		store = as
+	if as == nil {
+		as = GetStore()
 	}
+	store := trace(as)
 
 	if t.Kind() == reflect.Pointer {
 		pv = reflect.Indirect(v)
@@ -40,22 +237,32 @@ func resolveEmbedded(as Scope, t reflect.Type, v reflect.Value, dependencies []R
 		pt = t
 	}
 
-	for i := 0; i < pt.NumField(); i++ {
+	values, err := resolveEach(store, pt.NumField(), func(i int) (reflect.Value, error) {
 		f := pt.Field(i)
 		if f.Anonymous {
-			continue
+			return reflect.Value{}, nil
 		}
 
+		fs := store.within(f.Name)
 		if !f.IsExported() {
-			return pv, fmt.Errorf("unable to resolve unexported field: %s, field is not exported", f.Name)
+			return reflect.Value{}, failure(
+				MissingDependency,
+				fs,
+				fmt.Errorf("unable to resolve unexported field: %s, field is not exported", f.Name),
+				Step{Type: f.Type, Field: f.Name},
+			)
 		}
 
-		value, err := resolveType(store, f.Type, dependencies)
-		if err != nil {
-			return pv, fmt.Errorf("unable to resolve embedded %s\n%w", t.String(), err)
-		}
+		return resolveSlot(fs, fieldSlot(0, f), dependencies)
+	})
+	if err != nil {
+		return pv, err
+	}
 
-		pv.Field(i).Set(value)
+	for i, value := range values {
+		if value.IsValid() {
+			pv.Field(i).Set(value)
+		}
 	}
 
 	if t.Kind() == reflect.Pointer {
@@ -65,7 +272,46 @@ func resolveEmbedded(as Scope, t reflect.Type, v reflect.Value, dependencies []R
 	return pv, nil
 }
 
+// resolveEach calls resolve for each of n values, concurrently when the frame is parallel.
+// Sequential resolution stops at the first failure, parallel resolution joins all of them
+func resolveEach(f *frame, n int, resolve func(i int) (reflect.Value, error)) ([]reflect.Value, error) {
+	values := make([]reflect.Value, n)
+
+	if n < 2 || !f.isParallel() {
+		for i := range values {
+			v, err := resolve(i)
+			if err != nil {
+				return values, err
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], errs[i] = resolve(i)
+		}(i)
+	}
+	wg.Wait()
+
+	errs = slices.DeleteFunc(errs, func(e error) bool { return e == nil })
+	if len(errs) == 1 {
+		return values, errs[0]
+	}
+	return values, errors.Join(errs...)
+}
+
 func resolveType(store Scope, t reflect.Type, dependencies []Retrievable) (v reflect.Value, e error) {
+	return resolveSlot(store, slot{typ: t}, tiers{dependencies})
+}
+
+func resolveSlot(store Scope, s slot, dependencies tiers) (v reflect.Value, e error) {
+	t := s.typ
 	if isInEmbedded(t) {
 		var sv reflect.Value
 		if t.Kind() == reflect.Pointer {
@@ -74,20 +320,42 @@ func resolveType(store Scope, t reflect.Type, dependencies []Retrievable) (v ref
 			sv = reflect.New(t)
 		}
 
-		v, e = resolveEmbedded(store, t, sv, dependencies)
+		v, e = resolveFields(store, t, sv, dependencies)
 		return
 	}
 
-	for _, d := range dependencies {
-		if d.canResolve(t) {
-			vv, err := d.retrieve(store)
-			if err != nil {
-				return v, err
-			}
+	strict := isStrict(store)
+	candidates := s.candidates(dependencies, strict)
+	if len(candidates) > 1 && strict {
+		return v, failure(AmbiguousDependency, store, s.ambiguity(candidates), Step{Type: t, Field: trace(store).field})
+	}
+
+	if len(candidates) > 0 {
+		d := candidates[0]
+		if s.lazy {
+			return reflect.Zero(s.holder).Interface().(lazy).bind(detach(store), d), nil
+		}
+
+		vv, err := d.retrieve(store)
+		if err != nil {
+			return v, err
+		}
 
-			v = reflect.ValueOf(vv)
-			return
+		v = reflect.ValueOf(vv)
+		if s.holder != nil {
+			v = reflect.Zero(s.holder).Interface().(optional).with(v)
 		}
+		return
 	}
-	return v, fmt.Errorf("unable to resolve dependency for type: %s", t.String())
+
+	if s.optional {
+		return s.zero(), nil
+	}
+
+	return v, failure(
+		MissingDependency,
+		store,
+		fmt.Errorf("unable to resolve dependency for type: %s", s.String()),
+		Step{Type: t, Field: trace(store).field},
+	)
 }
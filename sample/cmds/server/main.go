@@ -1,20 +1,24 @@
 package main
 
 import (
+	"context"
+
+	"github.com/submodule-org/submodule.go/v2"
 	"github.com/submodule-org/submodule.go/v2/meta/mhttp"
 	"github.com/submodule-org/submodule.go/v2/sample"
 )
 
 func main() {
-	e := mhttp.ResolveRoutes(sample.EmptyHandlerRoute)
-	if e != nil {
-		panic(e)
-	}
-
 	mhttp.AlterConfig(func(c *mhttp.ServerConfig) {
 		c.Addr = ":19000"
 	})
 
+	// routes first, the server picks up the resolved ones
+	e := submodule.Warmup(context.Background(), submodule.GetStore(), sample.EmptyHandlerRoute, mhttp.Server)
+	if e != nil {
+		panic(e)
+	}
+
 	server := mhttp.Server.Resolve()
 	e = server.ListenAndServe()
 	panic(e)
@@ -2,22 +2,112 @@ package submodule
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type value struct {
 	value reflect.Value
 	e     reflect.Value
+	// time spent in the factory, dependencies excluded
+	took time.Duration
+}
+
+// call is an in-flight factory execution, concurrent resolvers of the same submodule wait on it
+type call struct {
+	done chan struct{}
+	v    *value
+	path []step
 }
 
 type scope struct {
 	mu     sync.Mutex
 	values map[Retrievable]*value
+	calls  map[Retrievable]*call
+	// paths of the submodules which timed out, by the time their build is over
+	stalls map[Retrievable][]step
+	// resolutions waiting on an in-flight build
+	waits map[*wait]struct{}
+	// submodules each submodule resolved while being built in the scope
+	edges map[Retrievable][]Retrievable
+	// start and stop hooks, in registration order
+	hooks []hook
+	// values taken over from the scope it was forked from, they can be overridden
+	forked map[Retrievable]bool
+	// submodules substituted or modified in the scope and its children
+	overrides map[Retrievable]override
 
 	parent     Scope
 	inherit    bool
 	middleware []Middleware
+	started    time.Time
+	deadline   time.Time
+	parallel   bool
+	state      scopeState
+}
+
+// scopeState tells where a scope is in its life
+type scopeState int
+
+const (
+	active scopeState = iota
+	disposing
+	disposed
+)
+
+// wait is a resolution, with the submodules it is building, blocked on the in-flight build of another one
+type wait struct {
+	path []step
+	on   Retrievable
+}
+
+// frame is the view of a scope handed to a factory while its submodule is being built.
+// It carries the chain of submodules under construction, so resolutions made through it can detect cycles
+// and report where they failed
+type frame struct {
+	*scope
+	ctx  context.Context
+	path []step
+	// field of the submodule being built that is currently resolved, if any
+	field string
+	// set once the build the frame was handed to is over, the frame then resolves like its scope
+	over *atomic.Bool
+}
+
+// step is a submodule under construction, field names the In struct or Resolve[T] field it is injected into
+type step struct {
+	r     Retrievable
+	field string
+}
+
+// trace returns the frame of a scope, a plain scope starts a new resolution with a background context.
+// So does a frame kept by a factory once its build is over, the caller context and path are gone with it
+func trace(s Scope) *frame {
+	if f, ok := s.(*frame); ok {
+		if f.over != nil && f.over.Load() {
+			return &frame{scope: f.scope, ctx: context.Background()}
+		}
+		return f
+	}
+
+	return &frame{scope: s.(*scope), ctx: context.Background()}
+}
+
+// detach keeps the path of a frame but not its context, for resolutions made once the current one is over
+func detach(s Scope) Scope {
+	f := trace(s)
+	return &frame{scope: f.scope, ctx: context.Background(), path: f.path}
+}
+
+// within derives a frame to resolve the given field, sharing the context and the path
+func (f *frame) within(field string) *frame {
+	return &frame{scope: f.scope, ctx: f.ctx, path: f.path, field: field}
 }
 
 // A scope is a container for retrievable values.
@@ -28,82 +118,278 @@ type scope struct {
 type Scope interface {
 	get(g Retrievable) *value
 	has(g Retrievable) bool
+	resolve(f *frame, g Retrievable, build func() (*value, bool)) (*value, error)
 
 	initValue(g Retrievable, v reflect.Value) *value
 	InitValue(g Retrievable, v any)
 	initError(g Retrievable, e reflect.Value) *value
 	InitError(g Retrievable, e error)
 
+	// Fork creates a scope holding the values (and errors) resolved in the scope so far.
+	// Values are shared, not copied, but overriding them or disposing the fork never touches the scope.
+	// Scope end hooks stay with the scope, the fork only runs those of the values it builds itself
+	Fork() Scope
+
 	Dispose() error
 	DisposeWithContext(ctx context.Context) error
 	AppendMiddleware(...Middleware)
 	Apply(Submodule[Middleware])
 }
 
-func (s *scope) has(g Retrievable) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// chain lists the scopes values are looked up in, from s to its outermost parent.
+// The global scope ends the chain when any of them inherits from it
+func (s *scope) chain() []*scope {
+	chain := []*scope{s}
+	inherit := s.inherit
+	for p := s; p.parent != nil; {
+		p = trace(p.parent).scope
+		chain = append(chain, p)
+		inherit = inherit || p.inherit
+	}
 
-	if s.inherit && globalScope.has(g) {
-		return true
+	if global := trace(globalScope).scope; inherit && chain[len(chain)-1] != global {
+		chain = append(chain, global)
 	}
+	return chain
+}
 
-	if s.parent != nil && s.parent.has(g) {
-		return true
+// lookup finds the value of g in the scope or, unless g is isolated, along its chain of parents.
+// Values found in a parent are not copied into the scope.
+// Parents of a scope overriding g or one of its dependencies are not consulted, their value is not the one asked for
+func (s *scope) lookup(g Retrievable) (*value, bool) {
+	chain := []*scope{s}
+	if g.options().pin != Isolated {
+		chain = s.chain()
 	}
 
-	_, ok := s.values[g]
+	for _, c := range chain {
+		c.mu.Lock()
+		v, ok := c.values[g]
+		c.mu.Unlock()
+
+		if ok {
+			return v, true
+		}
+		if c.overriding(g) {
+			break
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) has(g Retrievable) bool {
+	_, ok := s.lookup(g)
 	return ok
 }
 
 func (s *scope) get(g Retrievable) *value {
+	v, _ := s.lookup(g)
+	return v
+}
+
+// resolve returns the value of g in the scope. When it is missing, build is called exactly once
+// per scope; concurrent resolvers of g block until the in-flight build completes and share its result.
+// build reports whether its result should be cached, f is the frame g is built in.
+// Waiting on an in-flight build is abandoned with the context error once the frame context is done
+func (s *scope) resolve(f *frame, g Retrievable, build func() (*value, bool)) (*value, error) {
+	ctx := f.ctx
+
+	if v, ok := s.lookup(g); ok {
+		return v, nil
+	}
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if v, ok := s.values[g]; ok {
+		s.mu.Unlock()
+		return v, nil
+	}
 
-	var v *value
-	var ok bool
+	if c, ok := s.calls[g]; ok {
+		// the frame path ends with g, which is built by another resolution
+		building := f.path[:len(f.path)-1]
+		if loop := s.deadlock(building, g); loop != nil {
+			s.mu.Unlock()
+			return nil, newCycleError(loop)
+		}
 
-	if s.parent != nil && s.parent.has(g) {
-		v = s.parent.get(g)
-		s.values[g] = v
+		w := &wait{path: building, on: g}
+		s.waits[w] = struct{}{}
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			delete(s.waits, w)
+			s.mu.Unlock()
+		}()
+
+		select {
+		case <-c.done:
+			return c.v, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 
-		return v
+	if err := ctx.Err(); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	c := &call{done: make(chan struct{}), path: f.path}
+	s.calls[g] = c
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.calls, g)
+		if c.v != nil && isTimeout(c.v) {
+			s.stalls[g] = c.path
+		}
+		s.mu.Unlock()
+		close(c.done)
+	}()
+
+	v, cache := build()
+	if cache {
+		v = s.store(g, v)
+	}
+
+	c.v = v
+	return v, nil
+}
+
+// deadlock tells whether the in-flight build of g is, directly or not, waiting on one of the submodules
+// being built on the given path. Waiting would then block forever, the loop of submodules is returned instead.
+// Must be called with the lock held
+func (s *scope) deadlock(path []step, g Retrievable) []step {
+	chain := []step{{r: g}}
+	seen := map[Retrievable]bool{g: true}
+
+	for {
+		var next *wait
+		var from int
+		for w := range s.waits {
+			i := slices.IndexFunc(w.path, func(p step) bool { return p.r == chain[len(chain)-1].r })
+			if i >= 0 && !seen[w.on] {
+				next, from = w, i
+				break
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		chain = append(chain, next.path[from+1:]...)
+		if i := slices.IndexFunc(path, func(p step) bool { return p.r == next.on }); i >= 0 {
+			return append(append(slices.Clip(path[i:]), chain...), step{r: next.on})
+		}
+
+		seen[next.on] = true
+		chain = append(chain, step{r: next.on})
 	}
+}
+
+// stalled returns the innermost submodule being built on behalf of the given path
+func (s *scope) stalled(path []step) Retrievable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if s.inherit && globalScope.has(g) {
-		return globalScope.get(g)
+	deepest := path
+	consider := func(p []step) {
+		if len(p) > len(deepest) && slices.EqualFunc(p[:len(path)], path, func(a, b step) bool {
+			return a.r == b.r
+		}) {
+			deepest = p
+		}
 	}
 
-	v, ok = s.values[g]
-	if !ok {
-		g.retrieve(s)
-		v = s.values[g]
+	for _, c := range s.calls {
+		consider(c.path)
+	}
+	for _, p := range s.stalls {
+		consider(p)
 	}
 
+	return deepest[len(deepest)-1].r
+}
+
+// budget returns the start and the end of the startup budget, if the scope has one left.
+// Startup is over once the budget is spent, builds starting later are not bounded by it
+func (s *scope) budget() (start, end time.Time, ok bool) {
+	if s.deadline.IsZero() || !time.Now().Before(s.deadline) {
+		return start, end, false
+	}
+	return s.started, s.deadline, true
+}
+
+// store caches v as the value of g, unless g has been resolved in the scope already.
+// Values of parent scopes, and those taken over by a fork, do not count: the new value shadows them
+func (s *scope) store(g Retrievable, v *value) *value {
+	v = s.decorate(v)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.values[g]; ok && !s.forked[g] {
+		return existing
+	}
+	s.values[g] = v
+	delete(s.forked, g)
+
 	return v
 }
 
-func (s *scope) initValue(g Retrievable, v reflect.Value) *value {
-	if s.has(g) {
-		return s.get(g)
+// decorate applies the scope resolve middlewares to a value
+func (s *scope) decorate(v *value) *value {
+	if !v.value.IsValid() {
+		return v
 	}
 
-	args := []reflect.Value{v}
-	for _, m := range s.middleware {
-		if m.hasOnScopeResolve && v.Type().AssignableTo(m.onScopeResolveType) {
+	args := []reflect.Value{v.value}
+	for _, m := range s.middlewares() {
+		if m.hasOnScopeResolve && v.value.Type().AssignableTo(m.onScopeResolveType) {
 			args = m.onScopeResolve.Call(args)
 		}
 	}
 
-	value := &value{
-		value: args[0],
+	return &value{value: args[0], e: v.e, took: v.took}
+}
+
+// root is the outermost scope s inherits from
+func (s *scope) root() *scope {
+	chain := s.chain()
+	return chain[len(chain)-1]
+}
+
+// owner is the scope a submodule is built and cached in, when resolved from s.
+// An overridden submodule, or one depending on it, is never built above the scope overriding it,
+// a value set on a nearer scope with InitValue or ResolveToWith is the one used
+func (s *scope) owner(g Retrievable) *scope {
+	opts := g.options()
+	chain := s.chain()
+
+	at := 0
+	switch {
+	case opts.lifetime == RootSingleton:
+		at = len(chain) - 1
+	case opts.pin == Shared && len(chain) > 1:
+		at = 1
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.values[g] = value
+	for _, c := range chain[:at] {
+		c.mu.Lock()
+		_, ok := c.values[g]
+		c.mu.Unlock()
 
-	return value
+		if ok || c.overriding(g) {
+			return c
+		}
+	}
+	return chain[at]
+}
+
+func (s *scope) initValue(g Retrievable, v reflect.Value) *value {
+	return s.store(g, &value{value: v})
 }
 
 // A scope can enforce a submodule to be a specific value no matter what its factory returns.
@@ -114,19 +400,7 @@ func (s *scope) InitValue(g Retrievable, v any) {
 }
 
 func (s *scope) initError(g Retrievable, e reflect.Value) *value {
-	if s.has(g) {
-		return s.get(g)
-	}
-
-	value := &value{
-		e: e,
-	}
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.values[g] = value
-
-	return value
+	return s.store(g, &value{e: e})
 }
 
 // A scope can enforce a submodule to be a specific value no matter what its factory returns.
@@ -150,69 +424,180 @@ func (s *scope) release() {
 	for k := range s.values {
 		delete(s.values, k)
 	}
+	for k := range s.stalls {
+		delete(s.stalls, k)
+	}
+	for k := range s.edges {
+		delete(s.edges, k)
+	}
+	s.hooks = nil
+	clear(s.forked)
+	clear(s.overrides)
+
+	// hooks appended by factories belong to the released values, scope end hooks have run already
+	s.middleware = slices.DeleteFunc(s.middleware, func(m Middleware) bool {
+		return m.owner != nil || m.hasOnScopeEnd
+	})
+}
+
+// isDisposed tells whether the scope is disposed, values are not resolved anymore
+func (s *scope) isDisposed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state == disposed
 }
 
-type middlewareCaller func(Middleware) error
+// transition moves the scope from one state to another, it reports false when the scope is not in the expected state
+func (s *scope) transition(from, to scopeState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func (s *scope) dispose(cond middlewareCaller) error {
-	for i := len(s.middleware) - 1; i >= 0; i-- {
-		if s.middleware[i].hasOnScopeEnd {
-			if err := cond(s.middleware[i]); err != nil {
-				return err
+	if s.state != from {
+		return false
+	}
+	s.state = to
+	return true
+}
+
+// endHooks lists the scope end middlewares in the order they run.
+// Hooks appended by factories come first, a value is disposed before everything it depends on.
+// Other hooks follow, the last appended runs first
+func (s *scope) endHooks() []Middleware {
+	var owned, unowned []Middleware
+	var owners []Retrievable
+
+	for _, m := range s.middlewares() {
+		switch {
+		case !m.hasOnScopeEnd:
+		case m.owner == nil:
+			unowned = append(unowned, m)
+		default:
+			owned = append(owned, m)
+			if !slices.Contains(owners, m.owner) {
+				owners = append(owners, m.owner)
 			}
 		}
 	}
-	return nil
-}
 
-var disposeCond = func(m Middleware) error {
-	if m.onScopeEnd != nil {
-		return m.onScopeEnd()
+	var hooks []Middleware
+	order := s.dependencyOrder(owners)
+	for i := len(order) - 1; i >= 0; i-- {
+		for j := len(owned) - 1; j >= 0; j-- {
+			if owned[j].owner == order[i] {
+				hooks = append(hooks, owned[j])
+			}
+		}
 	}
-	return nil
+
+	slices.Reverse(unowned)
+	return append(hooks, unowned...)
 }
 
-var disposeWithContextCond = func(ctx context.Context) func(m Middleware) error {
-	return func(m Middleware) error {
-		if m.onScopeEndWithContext != nil {
-			return m.onScopeEndWithContext(ctx)
-		}
+// DisposeWithContext runs every scope end middleware and frees up all resolved values.
+// A disposed scope does not resolve anything anymore, disposing it again does nothing.
+// The global scope is the exception, it is active again once disposed, its scope end hooks are dropped once run.
+// Hooks run in a single pass, one failing does not stop the others, failures are returned joined with errors.Join.
+// Context aware hooks receive ctx. Once its deadline is reached, hooks still running or left are abandoned
+// and reported as context.DeadlineExceeded. Values are released in any case
+func (s *scope) DisposeWithContext(ctx context.Context) error {
+	if !s.transition(active, disposing) {
 		return nil
 	}
-}
 
-// DisposeWithContext dispose scope with context
-func (s *scope) DisposeWithContext(ctx context.Context) error {
-	if err := s.dispose(disposeWithContextCond(ctx)); err != nil {
-		return err
+	// the global scope lives as long as the program, it is ready to use again
+	if s == trace(globalScope).scope {
+		defer s.transition(disposing, active)
+	} else {
+		defer s.transition(disposing, disposed)
 	}
-	if err := s.dispose(disposeCond); err != nil {
-		return err
+
+	var deadline <-chan time.Time
+	if d, ok := ctx.Deadline(); ok {
+		timer := time.NewTimer(time.Until(d))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var errs []error
+	expired := false
+	for _, m := range s.endHooks() {
+		if !expired {
+			done := make(chan error, 1)
+			go func() {
+				done <- m.end(ctx)
+			}()
+
+			select {
+			case err := <-done:
+				if err != nil {
+					errs = append(errs, fmt.Errorf("disposing %s: %w", m.describe(), err))
+				}
+				continue
+			case <-deadline:
+				expired = true
+			}
+		}
+
+		errs = append(errs, fmt.Errorf("disposing %s: abandoned: %w", m.describe(), context.DeadlineExceeded))
 	}
+
 	s.release()
-	return nil
+	return errors.Join(errs...)
 }
 
-// Dispose scope to free up all resolved values and trigger scope end middlewares
+// Dispose scope to free up all resolved values and trigger scope end middlewares, see DisposeWithContext
 func (s *scope) Dispose() error {
-	if err := s.dispose(disposeWithContextCond(context.TODO())); err != nil {
-		return err
+	return s.DisposeWithContext(context.Background())
+}
+
+// end runs the scope end hook of the middleware
+func (m Middleware) end(ctx context.Context) error {
+	if m.onScopeEndWithContext != nil {
+		return m.onScopeEndWithContext(ctx)
 	}
-	if err := s.dispose(disposeCond); err != nil {
-		return err
+	if m.onScopeEnd != nil {
+		return m.onScopeEnd()
 	}
-	s.release()
 	return nil
 }
 
+// describe names the submodule which appended the middleware
+func (m Middleware) describe() string {
+	if m.owner == nil {
+		return "scope"
+	}
+	return m.owner.provides().String()
+}
+
 // Append middleware to the scope
 func (s *scope) AppendMiddleware(m ...Middleware) {
 	if len(m) == 0 {
 		return
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.middleware = append(s.middleware, m...)
 }
 
+// AppendMiddleware appended by a factory through Self.Scope are owned by the submodule being built,
+// their scope end hooks run in dependency order
+func (f *frame) AppendMiddleware(m ...Middleware) {
+	owned := slices.Clone(m)
+	for i := range owned {
+		owned[i].owner = f.owner()
+	}
+	f.scope.AppendMiddleware(owned...)
+}
+
+// snapshot of the middleware list, safe to iterate while factories keep appending
+func (s *scope) middlewares() []Middleware {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Middleware(nil), s.middleware...)
+}
+
 // Append global middleware to the global scope
 func AppendGlobalMiddleware(ms ...Middleware) {
 	if len(ms) == 0 {
@@ -222,6 +607,7 @@ func AppendGlobalMiddleware(ms ...Middleware) {
 }
 
 // Dispose global scope to free up all resolved values and trigger scope end middlewares
+// The global scope is active again once disposed, values are rebuilt on demand
 func DisposeGlobalScope() error {
 	return globalScope.Dispose()
 }
@@ -248,6 +634,9 @@ type Middleware struct {
 
 	onScopeEnd            func() error
 	onScopeEndWithContext func(context.Context) error
+
+	// submodule which appended the middleware while being built, if any
+	owner Retrievable
 }
 
 type MiddlewareFn func(Middleware) Middleware
@@ -278,6 +667,8 @@ type ScopeOpts struct {
 	inherit     bool
 	parent      Scope
 	middlewares []Middleware
+	budget      time.Duration
+	parallel    bool
 }
 
 type ScopeOptsFn func(opts ScopeOpts) ScopeOpts
@@ -307,10 +698,51 @@ func WithMiddlewares(middlewares ...Middleware) ScopeOptsFn {
 	}
 }
 
+// WithStartupBudget bounds the time spent building submodules in the scope, counting from its creation.
+// Once the budget is spent, builds still running fail with a *TimeoutError naming the one stalled,
+// values resolved in time stay available. Builds starting after the budget, transient values or lazy
+// dependencies resolved later on, are not bounded by it
+func WithStartupBudget(budget time.Duration) ScopeOptsFn {
+	return func(opts ScopeOpts) ScopeOpts {
+		opts.budget = budget
+		return opts
+	}
+}
+
+// WithParallel resolves the dependencies of a factory concurrently, parameters and In fields alike,
+// so building a submodule takes as long as its slowest dependency rather than the sum of them.
+// Each submodule is still built once per scope, failures of all dependencies are joined.
+// ParallelContext does the same for a single resolution
+func WithParallel() ScopeOptsFn {
+	return func(opts ScopeOpts) ScopeOpts {
+		opts.parallel = true
+		return opts
+	}
+}
+
+type parallelKey struct{}
+
+// ParallelContext turns parallel resolution on for resolutions made with the returned context, see WithParallel
+//
+//	server, err := ServerMod.SafeResolveContext(submodule.ParallelContext(ctx), scope)
+func ParallelContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, parallelKey{}, true)
+}
+
+// isParallel tells whether the dependencies of the submodule being built in the frame are resolved concurrently
+func (f *frame) isParallel() bool {
+	on, _ := f.ctx.Value(parallelKey{}).(bool)
+	return on || f.scope.parallel
+}
+
 // Create a new scope with modifiers
 func CreateScope(fns ...ScopeOptsFn) Scope {
 	s := &scope{
 		values: make(map[Retrievable]*value),
+		calls:  make(map[Retrievable]*call),
+		stalls: make(map[Retrievable][]step),
+		waits:  make(map[*wait]struct{}),
+		edges:  make(map[Retrievable][]Retrievable),
 	}
 
 	opt := ScopeOpts{}
@@ -319,7 +751,16 @@ func CreateScope(fns ...ScopeOptsFn) Scope {
 	}
 
 	s.inherit = opt.inherit
-	s.parent = opt.parent
+	if opt.parent != nil {
+		// a factory may hand its Self.Scope, the scope is kept rather than the resolution it was given for
+		s.parent = trace(opt.parent).scope
+	}
+	s.parallel = opt.parallel
+
+	if opt.budget > 0 {
+		s.started = time.Now()
+		s.deadline = s.started.Add(opt.budget)
+	}
 
 	if len(opt.middlewares) > 0 {
 		s.middleware = opt.middlewares
@@ -333,6 +774,55 @@ var globalScope = CreateScope(
 	WithParent(nil),
 )
 
+func (s *scope) Fork() Scope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := &scope{
+		values:    maps.Clone(s.values),
+		calls:     make(map[Retrievable]*call),
+		stalls:    make(map[Retrievable][]step),
+		waits:     make(map[*wait]struct{}),
+		edges:     make(map[Retrievable][]Retrievable, len(s.edges)),
+		forked:    make(map[Retrievable]bool, len(s.values)),
+		overrides: maps.Clone(s.overrides),
+		parent:    s.parent,
+		inherit:   s.inherit,
+		started:   s.started,
+		deadline:  s.deadline,
+		parallel:  s.parallel,
+	}
+
+	for k := range s.values {
+		f.forked[k] = true
+	}
+	for k, v := range s.edges {
+		f.edges[k] = slices.Clone(v)
+	}
+
+	for _, m := range s.middleware {
+		if !m.hasOnScopeEnd {
+			f.middleware = append(f.middleware, m)
+		}
+	}
+
+	return f
+}
+
+type scopeKey struct{}
+
+// ContextWithScope attaches a scope to a context, code reached with the context can resolve against it
+// with SafeResolveFrom instead of the global scope
+func ContextWithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext returns the scope attached to the context with ContextWithScope
+func ScopeFromContext(ctx context.Context) (Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(Scope)
+	return scope, ok
+}
+
 // Return the global scope
 func GetStore() Scope {
 	return globalScope
@@ -2,10 +2,15 @@ package submodule_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/submodule-org/submodule.go/v2"
 )
 
@@ -82,3 +87,745 @@ func Test_Scope_With_Context(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, isDispose)
 }
+
+func Test_Scope_Concurrent_Resolution(t *testing.T) {
+	const workers = 64
+
+	t.Run("factory runs once per scope", func(t *testing.T) {
+		var calls atomic.Int32
+		var closed atomic.Int32
+
+		client := submodule.Make[*Counter](func(self submodule.Self) *Counter {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				closed.Add(1)
+				return nil
+			}))
+			return &Counter{}
+		})
+
+		scope := submodule.CreateScope()
+		results := make([]*Counter, workers)
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i%2 == 0 {
+					results[i] = client.ResolveWith(scope)
+					return
+				}
+
+				c, e := client.SafeResolveWith(scope)
+				assert.NoError(t, e)
+				results[i] = c
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		for _, r := range results {
+			assert.Same(t, results[0], r)
+		}
+
+		require.NoError(t, scope.Dispose())
+		assert.Equal(t, int32(1), closed.Load())
+	})
+
+	t.Run("waiters share the error", func(t *testing.T) {
+		var calls atomic.Int32
+
+		failing := submodule.Make[int](func() (int, error) {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return 0, fmt.Errorf("dial failed")
+		})
+
+		scope := submodule.CreateScope()
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, e := failing.SafeResolveWith(scope)
+				assert.ErrorContains(t, e, "dial failed")
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("shared dependency runs once", func(t *testing.T) {
+		var calls atomic.Int32
+
+		shared := submodule.Make[*Counter](func() *Counter {
+			calls.Add(1)
+			time.Sleep(10 * time.Millisecond)
+			return &Counter{}
+		})
+
+		left := submodule.Make[string](func(c *Counter) string {
+			return "left"
+		}, shared)
+
+		right := submodule.Make[int](func(c *Counter) int {
+			return 1
+		}, shared)
+
+		scope := submodule.CreateScope()
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				left.ResolveWith(scope)
+			}()
+			go func() {
+				defer wg.Done()
+				right.ResolveWith(scope)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("each scope runs its own factory", func(t *testing.T) {
+		var calls atomic.Int32
+
+		counter := submodule.Make[*Counter](func() *Counter {
+			calls.Add(1)
+			return &Counter{}
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				counter.ResolveWith(submodule.CreateScope())
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(workers), calls.Load())
+	})
+}
+
+type (
+	timeoutDb     struct{}
+	timeoutServer struct{}
+)
+
+func Test_Scope_Timeout(t *testing.T) {
+	t.Run("submodule timeout", func(t *testing.T) {
+		db := submodule.Make[*timeoutDb](func(ctx context.Context) (*timeoutDb, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, submodule.WithTimeout(10*time.Millisecond))
+
+		scope := submodule.CreateScope()
+		_, e := db.SafeResolveWith(scope)
+		require.ErrorIs(t, e, context.DeadlineExceeded)
+
+		var te *submodule.TimeoutError
+		require.ErrorAs(t, e, &te)
+		assert.Equal(t, "*submodule_test.timeoutDb", te.Type.String())
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.Timeout, re.Kind)
+
+		_, cached := db.SafeResolveWith(scope)
+		assert.Equal(t, e, cached)
+	})
+
+	t.Run("startup budget names the stalled dependency", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		db := submodule.Make[*timeoutDb](func() *timeoutDb {
+			<-release
+			return &timeoutDb{}
+		})
+
+		server := submodule.Make[*timeoutServer](func(db *timeoutDb) *timeoutServer {
+			return &timeoutServer{}
+		}, db)
+
+		scope := submodule.CreateScope(submodule.WithStartupBudget(20 * time.Millisecond))
+		_, e := server.SafeResolveWith(scope)
+
+		var te *submodule.TimeoutError
+		require.ErrorAs(t, e, &te)
+		assert.Equal(t, "*submodule_test.timeoutDb", te.Type.String())
+		assert.GreaterOrEqual(t, te.After, 20*time.Millisecond)
+	})
+
+	t.Run("startup budget does not bound builds starting after it", func(t *testing.T) {
+		transient := submodule.Make[*int](func() *int {
+			return new(int)
+		}, submodule.WithLifetime(submodule.Transient))
+
+		db := submodule.Make[*timeoutDb](func() *timeoutDb {
+			return &timeoutDb{}
+		})
+
+		scope := submodule.CreateScope(submodule.WithStartupBudget(20 * time.Millisecond))
+		time.Sleep(30 * time.Millisecond)
+
+		_, e := transient.SafeResolveWith(scope)
+		require.NoError(t, e)
+		_, e = db.SafeResolveWith(scope)
+		require.NoError(t, e)
+	})
+
+	t.Run("timeout of a dependency applies within a parent", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		db := submodule.MakeModifiable[*timeoutDb](func() *timeoutDb {
+			<-release
+			return &timeoutDb{}
+		}, submodule.WithTimeout(10*time.Millisecond))
+
+		server := submodule.Make[*timeoutServer](func(db *timeoutDb) *timeoutServer {
+			return &timeoutServer{}
+		}, db)
+
+		_, e := server.SafeResolveWith(submodule.CreateScope())
+
+		var te *submodule.TimeoutError
+		require.ErrorAs(t, e, &te)
+		assert.Equal(t, "*submodule_test.timeoutDb", te.Type.String())
+	})
+
+	t.Run("resolving in time is unaffected", func(t *testing.T) {
+		db := submodule.Make[*timeoutDb](func() *timeoutDb {
+			return &timeoutDb{}
+		}, submodule.WithTimeout(time.Second))
+
+		scope := submodule.CreateScope(submodule.WithStartupBudget(time.Second))
+		x, e := db.SafeResolveWith(scope)
+		require.NoError(t, e)
+		assert.Same(t, x, db.ResolveWith(scope))
+	})
+}
+
+func Test_Scope_Lifetime(t *testing.T) {
+	t.Run("transient builds on every resolution", func(t *testing.T) {
+		var built, disposed int
+		buffer := submodule.Make[*Counter](func(self submodule.Self) *Counter {
+			built++
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				disposed++
+				return nil
+			}))
+			return &Counter{}
+		}, submodule.WithLifetime(submodule.Transient))
+
+		user := submodule.Make[int](func(p struct {
+			submodule.In
+			First  *Counter
+			Second *Counter
+		}) int {
+			assert.NotSame(t, p.First, p.Second)
+			return 0
+		}, buffer)
+
+		scope := submodule.CreateScope()
+		user.ResolveWith(scope)
+		assert.NotSame(t, buffer.ResolveWith(scope), buffer.ResolveWith(scope))
+		assert.Equal(t, 4, built)
+
+		require.NoError(t, scope.Dispose())
+		assert.Equal(t, 4, disposed)
+	})
+
+	t.Run("transient can still be forced to a value", func(t *testing.T) {
+		buffer := submodule.Make[*Counter](func() *Counter {
+			return &Counter{}
+		}, submodule.WithLifetime(submodule.Transient))
+
+		scope := submodule.CreateScope()
+		forced := &Counter{Count: 10}
+		buffer.ResolveToWith(scope, forced)
+		assert.Same(t, forced, buffer.ResolveWith(scope))
+	})
+
+	t.Run("root singleton can be forced in a child scope", func(t *testing.T) {
+		built := 0
+		pool := submodule.Make[*Counter](func() *Counter {
+			built++
+			return &Counter{}
+		}, submodule.WithLifetime(submodule.RootSingleton))
+
+		root := submodule.CreateScope()
+		child := submodule.CreateScope(submodule.WithParent(root))
+
+		forced := &Counter{Count: 10}
+		pool.ResolveToWith(child, forced)
+		assert.Same(t, forced, pool.ResolveWith(child))
+		assert.Same(t, forced, pool.ResolveWith(submodule.CreateScope(submodule.WithParent(child))))
+		assert.NotSame(t, forced, pool.ResolveWith(root))
+		assert.Equal(t, 1, built)
+	})
+
+	t.Run("root singleton is shared by child scopes", func(t *testing.T) {
+		built := 0
+		pool := submodule.Make[*Counter](func() *Counter {
+			built++
+			return &Counter{}
+		}, submodule.WithLifetime(submodule.RootSingleton))
+
+		root := submodule.CreateScope()
+		first := submodule.CreateScope(submodule.WithParent(root))
+		second := submodule.CreateScope(submodule.WithParent(submodule.CreateScope(submodule.WithParent(root))))
+
+		assert.Same(t, pool.ResolveWith(first), pool.ResolveWith(second))
+		assert.Same(t, pool.ResolveWith(first), pool.ResolveWith(root))
+		assert.Equal(t, 1, built)
+	})
+}
+
+type (
+	parallelRedis  struct{}
+	parallelPg     struct{}
+	parallelConfig struct{}
+)
+
+func Test_Scope_Parallel(t *testing.T) {
+	var built atomic.Int32
+	slow := func(d time.Duration) {
+		built.Add(1)
+		time.Sleep(d)
+	}
+
+	config := submodule.Make[*parallelConfig](func() *parallelConfig {
+		slow(50 * time.Millisecond)
+		return &parallelConfig{}
+	})
+
+	redis := submodule.Make[*parallelRedis](func(c *parallelConfig) *parallelRedis {
+		slow(50 * time.Millisecond)
+		return &parallelRedis{}
+	}, config)
+
+	pg := submodule.Make[*parallelPg](func(c *parallelConfig) *parallelPg {
+		slow(50 * time.Millisecond)
+		return &parallelPg{}
+	}, config)
+
+	app := submodule.Make[string](func(p struct {
+		submodule.In
+		Redis  *parallelRedis
+		Pg     *parallelPg
+		Config *parallelConfig
+	}) string {
+		return "ready"
+	}, redis, pg, config)
+
+	t.Run("sibling dependencies are resolved concurrently", func(t *testing.T) {
+		built.Store(0)
+		start := time.Now()
+
+		s, e := app.SafeResolveWith(submodule.CreateScope(submodule.WithParallel()))
+		require.NoError(t, e)
+		assert.Equal(t, "ready", s)
+
+		assert.Less(t, time.Since(start), 140*time.Millisecond)
+		assert.Equal(t, int32(3), built.Load())
+	})
+
+	t.Run("parallel resolution can be asked per call", func(t *testing.T) {
+		start := time.Now()
+
+		_, e := app.SafeResolveContext(submodule.ParallelContext(context.Background()), submodule.CreateScope())
+		require.NoError(t, e)
+		assert.Less(t, time.Since(start), 140*time.Millisecond)
+	})
+
+	t.Run("failures are joined", func(t *testing.T) {
+		errRedis := errors.New("redis is down")
+		errPg := errors.New("pg is down")
+
+		app := submodule.Make[string](func(r *parallelRedis, p *parallelPg) string {
+			return "ready"
+		}, submodule.Make[*parallelRedis](func() (*parallelRedis, error) {
+			return nil, errRedis
+		}), submodule.Make[*parallelPg](func() (*parallelPg, error) {
+			return nil, errPg
+		}))
+
+		_, e := app.SafeResolveWith(submodule.CreateScope(submodule.WithParallel()))
+		assert.ErrorIs(t, e, errRedis)
+		assert.ErrorIs(t, e, errPg)
+	})
+
+	t.Run("waiting on each other is a cycle", func(t *testing.T) {
+		var pg submodule.Submodule[*parallelPg]
+
+		// by the time redis asks for pg, pg is waiting on redis through config
+		redis := submodule.Make[*parallelRedis](func(self submodule.Self) (*parallelRedis, error) {
+			time.Sleep(40 * time.Millisecond)
+			_, e := pg.SafeResolveWith(self.Scope)
+			return &parallelRedis{}, e
+		})
+
+		config := submodule.Make[*parallelConfig](func(self submodule.Self) (*parallelConfig, error) {
+			time.Sleep(20 * time.Millisecond)
+			_, e := redis.SafeResolveWith(self.Scope)
+			return &parallelConfig{}, e
+		})
+
+		pg = submodule.Make[*parallelPg](func(c *parallelConfig) *parallelPg {
+			return &parallelPg{}
+		}, config)
+
+		app := submodule.Make[string](func(r *parallelRedis, p *parallelPg) string {
+			return "ready"
+		}, redis, pg)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, e := app.SafeResolveContext(ctx, submodule.CreateScope(submodule.WithParallel()))
+
+		var ce *submodule.CycleError
+		require.ErrorAs(t, e, &ce)
+		assert.Equal(t, "circular dependency detected: *submodule_test.parallelRedis -> *submodule_test.parallelPg -> *submodule_test.parallelConfig -> *submodule_test.parallelRedis", ce.Error())
+	})
+}
+
+type (
+	disposeLogger struct{}
+	disposeRedis  struct{}
+	disposeCache  struct{}
+)
+
+func Test_Scope_Dispose_Order(t *testing.T) {
+	var closed []string
+	closing := func(name string) submodule.Middleware {
+		return submodule.WithScopeEnd(func() error {
+			closed = append(closed, name)
+			return nil
+		})
+	}
+
+	logger := submodule.Make[*disposeLogger](func(self submodule.Self) *disposeLogger {
+		self.Scope.AppendMiddleware(closing("logger"))
+		return &disposeLogger{}
+	})
+
+	redis := submodule.Make[*disposeRedis](func(self submodule.Self) (*disposeRedis, error) {
+		// the hook is appended before the logger is resolved, redis still closes first
+		self.Scope.AppendMiddleware(closing("redis"))
+		_, e := logger.SafeResolveWith(self.Scope)
+		return &disposeRedis{}, e
+	})
+
+	cache := submodule.Make[*disposeCache](func(self submodule.Self, r *disposeRedis) *disposeCache {
+		self.Scope.AppendMiddleware(closing("cache"))
+		return &disposeCache{}
+	}, redis)
+
+	scope := submodule.CreateScope(submodule.WithMiddlewares(closing("scope")))
+	scope.AppendMiddleware(closing("appended"))
+
+	_, e := cache.SafeResolveWith(scope)
+	require.NoError(t, e)
+
+	require.NoError(t, scope.Dispose())
+	assert.Equal(t, []string{"cache", "redis", "logger", "appended", "scope"}, closed)
+}
+
+func Test_Scope_Dispose_Errors(t *testing.T) {
+	t.Run("every hook runs, failures are joined", func(t *testing.T) {
+		var closed []string
+		errRedis := errors.New("redis close failed")
+
+		logger := submodule.Make[*disposeLogger](func(self submodule.Self) *disposeLogger {
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				closed = append(closed, "logger")
+				return nil
+			}))
+			return &disposeLogger{}
+		})
+
+		var built int
+		redis := submodule.Make[*disposeRedis](func(self submodule.Self, l *disposeLogger) *disposeRedis {
+			built++
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				closed = append(closed, "redis")
+				return errRedis
+			}))
+			return &disposeRedis{}
+		}, logger)
+
+		scope := submodule.CreateScope(submodule.WithMiddlewares(submodule.WithContextScopeEnd(func(ctx context.Context) error {
+			return errors.New("scope close failed")
+		})))
+		redis.ResolveWith(scope)
+
+		e := scope.Dispose()
+		assert.ErrorIs(t, e, errRedis)
+		assert.ErrorContains(t, e, "disposing *submodule_test.disposeRedis: redis close failed")
+		assert.ErrorContains(t, e, "disposing scope: scope close failed")
+		assert.Equal(t, []string{"redis", "logger"}, closed)
+
+		_, e = redis.SafeResolveWith(scope)
+		assert.ErrorIs(t, e, submodule.ErrScopeDisposed)
+		assert.Equal(t, 1, built)
+	})
+
+	t.Run("hooks are abandoned at the deadline", func(t *testing.T) {
+		var closed []string
+
+		logger := submodule.Make[*disposeLogger](func(self submodule.Self) *disposeLogger {
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				closed = append(closed, "logger")
+				return nil
+			}))
+			return &disposeLogger{}
+		})
+
+		redis := submodule.Make[*disposeRedis](func(self submodule.Self, l *disposeLogger) *disposeRedis {
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				time.Sleep(200 * time.Millisecond)
+				return nil
+			}))
+			return &disposeRedis{}
+		}, logger)
+
+		scope := submodule.CreateScope()
+		redis.ResolveWith(scope)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		e := scope.DisposeWithContext(ctx)
+		assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+		assert.ErrorIs(t, e, context.DeadlineExceeded)
+		assert.ErrorContains(t, e, "disposing *submodule_test.disposeRedis: abandoned")
+		assert.ErrorContains(t, e, "disposing *submodule_test.disposeLogger: abandoned")
+		assert.Empty(t, closed)
+	})
+}
+
+func Test_Scope_State(t *testing.T) {
+	var closed int
+	logger := submodule.Make[*disposeLogger](func(self submodule.Self) *disposeLogger {
+		self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+			closed++
+			return nil
+		}))
+		return &disposeLogger{}
+	})
+
+	t.Run("disposing twice does nothing", func(t *testing.T) {
+		closed = 0
+		scope := submodule.CreateScope()
+		logger.ResolveWith(scope)
+
+		require.NoError(t, scope.Dispose())
+		require.NoError(t, scope.Dispose())
+		assert.Equal(t, 1, closed)
+
+		_, e := logger.SafeResolveWith(scope)
+		assert.ErrorIs(t, e, submodule.ErrScopeDisposed)
+	})
+
+	t.Run("global scope is reopened, factory hooks go with their values", func(t *testing.T) {
+		closed = 0
+		logger.Resolve()
+		require.NoError(t, submodule.DisposeGlobalScope())
+		assert.Equal(t, 1, closed)
+
+		logger.Resolve()
+		require.NoError(t, submodule.DisposeGlobalScope())
+		assert.Equal(t, 2, closed)
+	})
+
+	t.Run("global scope end hooks run once", func(t *testing.T) {
+		ends := 0
+		submodule.AppendGlobalMiddleware(submodule.WithScopeEnd(func() error {
+			ends++
+			return nil
+		}))
+
+		require.NoError(t, submodule.DisposeGlobalScope())
+		require.NoError(t, submodule.DisposeGlobalScope())
+		assert.Equal(t, 1, ends)
+	})
+}
+
+type (
+	chainPool struct{ id int }
+	chainTx   struct{ pool *chainPool }
+)
+
+func Test_Scope_Parent_Chain(t *testing.T) {
+	var pools, txs int
+	newPool := func() *chainPool {
+		pools++
+		return &chainPool{id: pools}
+	}
+
+	t.Run("values are looked up along the chain, never copied", func(t *testing.T) {
+		pools = 0
+		pool := submodule.Make[*chainPool](newPool)
+
+		app := submodule.CreateScope()
+		tenant := submodule.CreateScope(submodule.WithParent(app))
+		request := submodule.CreateScope(submodule.WithParent(tenant))
+
+		p := pool.ResolveWith(app)
+		assert.Same(t, p, pool.ResolveWith(request))
+
+		// a value set on the child shadows the parent one, the parent keeps its own
+		other := &chainPool{}
+		pool.ResolveToWith(tenant, other)
+		assert.Same(t, other, pool.ResolveWith(request))
+		assert.Same(t, p, pool.ResolveWith(app))
+		assert.Equal(t, 1, pools)
+	})
+
+	t.Run("nearest submodules are built in the resolving scope", func(t *testing.T) {
+		pools = 0
+		pool := submodule.Make[*chainPool](newPool)
+
+		app := submodule.CreateScope()
+		first := submodule.CreateScope(submodule.WithParent(app))
+		second := submodule.CreateScope(submodule.WithParent(app))
+
+		assert.NotSame(t, pool.ResolveWith(first), pool.ResolveWith(second))
+		assert.Equal(t, 2, pools)
+	})
+
+	t.Run("shared submodules are built in the parent", func(t *testing.T) {
+		pools, txs = 0, 0
+		pool := submodule.Make[*chainPool](newPool, submodule.WithPin(submodule.Shared))
+		tx := submodule.Make[*chainTx](func(p *chainPool) *chainTx {
+			txs++
+			return &chainTx{pool: p}
+		}, pool, submodule.WithPin(submodule.Isolated))
+
+		app := submodule.CreateScope()
+		first := submodule.CreateScope(submodule.WithParent(app))
+		second := submodule.CreateScope(submodule.WithParent(app))
+
+		a, b := tx.ResolveWith(first), tx.ResolveWith(second)
+		assert.NotSame(t, a, b)
+		assert.Same(t, a.pool, b.pool)
+		assert.Same(t, a.pool, pool.ResolveWith(app))
+		assert.Equal(t, 1, pools)
+		assert.Equal(t, 2, txs)
+
+		// siblings do not share a child scope value, even once the parent has one
+		tx.ResolveWith(app)
+		assert.NotSame(t, tx.ResolveWith(app), tx.ResolveWith(submodule.CreateScope(submodule.WithParent(app))))
+		assert.Equal(t, 4, txs)
+	})
+
+	t.Run("shared submodules can be forced in the child", func(t *testing.T) {
+		pools = 0
+		pool := submodule.Make[*chainPool](newPool, submodule.WithPin(submodule.Shared))
+
+		app := submodule.CreateScope()
+		request := submodule.CreateScope(submodule.WithParent(app))
+
+		forced := &chainPool{}
+		pool.ResolveToWith(request, forced)
+		assert.Same(t, forced, pool.ResolveWith(request))
+		assert.NotSame(t, forced, pool.ResolveWith(app))
+		assert.Equal(t, 1, pools)
+	})
+
+	t.Run("inherit ends the chain with the global scope", func(t *testing.T) {
+		pools = 0
+		pool := submodule.Make[*chainPool](newPool)
+		p := pool.Resolve()
+		defer submodule.DisposeGlobalScope()
+
+		app := submodule.CreateScope(submodule.Inherit(true))
+		request := submodule.CreateScope(submodule.WithParent(app))
+		assert.Same(t, p, pool.ResolveWith(request))
+
+		isolated := submodule.CreateScope(submodule.WithParent(submodule.CreateScope()))
+		assert.NotSame(t, p, pool.ResolveWith(isolated))
+	})
+}
+
+func Test_Scope_Fork(t *testing.T) {
+	var pools, ends int
+	pool := submodule.Make[*chainPool](func(self submodule.Self) *chainPool {
+		pools++
+		self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+			ends++
+			return nil
+		}))
+		return &chainPool{id: pools}
+	})
+	failing := submodule.Make[int](func() (int, error) {
+		return 0, errors.New("no int")
+	})
+
+	base := submodule.CreateScope()
+	p := pool.ResolveWith(base)
+	_, baseErr := failing.SafeResolveWith(base)
+	require.Error(t, baseErr)
+
+	t.Run("values and errors are shared with the fork", func(t *testing.T) {
+		fork := base.Fork()
+		assert.Same(t, p, pool.ResolveWith(fork))
+
+		_, e := failing.SafeResolveWith(fork)
+		assert.Equal(t, baseErr, e)
+		assert.Equal(t, 1, pools)
+	})
+
+	t.Run("overrides stay in the fork", func(t *testing.T) {
+		fork := base.Fork()
+		other := &chainPool{}
+		pool.ResolveToWith(fork, other)
+		failing.ResolveToWith(fork, 1)
+
+		assert.Same(t, other, pool.ResolveWith(fork))
+		assert.Equal(t, 1, failing.ResolveWith(fork))
+		assert.Same(t, p, pool.ResolveWith(base))
+		_, e := failing.SafeResolveWith(base)
+		assert.Error(t, e)
+	})
+
+	t.Run("disposing the fork leaves the scope alone", func(t *testing.T) {
+		ends = 0
+		fork := base.Fork()
+		require.NoError(t, fork.Dispose())
+		assert.Equal(t, 0, ends)
+		assert.Same(t, p, pool.ResolveWith(base))
+
+		// values built by the fork are its own to dispose
+		var txEnds int
+		tx := submodule.Make[*chainTx](func(self submodule.Self, p *chainPool) *chainTx {
+			self.Scope.AppendMiddleware(submodule.WithScopeEnd(func() error {
+				txEnds++
+				return nil
+			}))
+			return &chainTx{pool: p}
+		}, pool)
+
+		fork = base.Fork()
+		assert.Same(t, p, tx.ResolveWith(fork).pool)
+		require.NoError(t, fork.Dispose())
+		assert.Equal(t, 1, txEnds)
+		assert.Equal(t, 0, ends)
+	})
+}
@@ -1,8 +1,11 @@
 package submodule_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -326,10 +329,25 @@ func TestModuleFunction(t *testing.T) {
 		require.Nil(t, e)
 
 		y.Append(submodule.Value(7))
-		z, e = y.SafeResolveWith(s)
+		z, e = y.SafeResolveWith(submodule.CreateScope())
 		require.Nil(t, e)
 		require.Equal(t, 8, z)
 	})
+
+	t.Run("substitute replaces a modifiable submodule", func(t *testing.T) {
+		y := submodule.MakeModifiable[int](func(x int) int {
+			return x + 1
+		}, submodule.Value(5))
+		y.Append(submodule.Value(7))
+
+		y.Substitute(submodule.Make[int](func(x int) int {
+			return x * 10
+		}, submodule.Value(3)))
+
+		z, e := y.SafeResolveWith(submodule.CreateScope())
+		require.Nil(t, e)
+		require.Equal(t, 30, z)
+	})
 	t.Run("use variadic modifiable submodule", func(t *testing.T) {
 		type (
 			num1 int
@@ -397,3 +415,376 @@ func (a As) Hello() {
 func (a *As) Goodbye() {
 	fmt.Println("goodbye")
 }
+
+type ctxKey struct{}
+
+func TestContext(t *testing.T) {
+	t.Run("factory receives the caller context", func(t *testing.T) {
+		m := submodule.Make[string](func(ctx context.Context) string {
+			return ctx.Value(ctxKey{}).(string)
+		})
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "from caller")
+		s, e := m.SafeResolveContext(ctx, submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "from caller", s)
+	})
+
+	t.Run("context reaches nested factories", func(t *testing.T) {
+		inner := submodule.Make[string](func(ctx context.Context) string {
+			return ctx.Value(ctxKey{}).(string)
+		})
+
+		outer := submodule.Make[string](func(p struct {
+			submodule.In
+			Inner string
+		}) string {
+			return "outer " + p.Inner
+		}, inner)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "inner")
+		s, e := outer.SafeResolveContext(ctx, submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "outer inner", s)
+	})
+
+	t.Run("done context aborts resolution", func(t *testing.T) {
+		called := false
+		m := submodule.Make[int](func() int {
+			called = true
+			return 1
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		scope := submodule.CreateScope()
+		_, e := m.SafeResolveContext(ctx, scope)
+		require.ErrorIs(t, e, context.Canceled)
+		require.False(t, called)
+
+		i, e := m.SafeResolveWith(scope)
+		require.NoError(t, e)
+		require.Equal(t, 1, i)
+	})
+
+	t.Run("waiting on an in-flight factory honors the context", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		m := submodule.Make[int](func() int {
+			close(started)
+			<-release
+			return 1
+		})
+
+		scope := submodule.CreateScope()
+		go m.ResolveWith(scope)
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, e := m.SafeResolveContext(ctx, scope)
+		require.ErrorIs(t, e, context.DeadlineExceeded)
+
+		close(release)
+		i, e := m.SafeResolveWith(scope)
+		require.NoError(t, e)
+		require.Equal(t, 1, i)
+	})
+
+	t.Run("factory giving up on a done context is not cached", func(t *testing.T) {
+		m := submodule.Make[int](func(ctx context.Context) (int, error) {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(10 * time.Millisecond):
+				return 1, nil
+			}
+		})
+
+		scope := submodule.CreateScope()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		_, e := m.SafeResolveContext(ctx, scope)
+		require.ErrorIs(t, e, context.DeadlineExceeded)
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		require.Equal(t, submodule.Canceled, re.Kind)
+
+		i, e := m.SafeResolveWith(scope)
+		require.NoError(t, e)
+		require.Equal(t, 1, i)
+	})
+
+	t.Run("a kept Self scope outlives the caller context", func(t *testing.T) {
+		var kept submodule.Scope
+		holder := submodule.Make[string](func(self submodule.Self) string {
+			kept = self.Scope
+			return "holder"
+		})
+		other := submodule.Make[int](func() int {
+			return 1
+		})
+
+		scope := submodule.CreateScope()
+		ctx, cancel := context.WithCancel(context.Background())
+		_, e := holder.SafeResolveContext(ctx, scope)
+		require.NoError(t, e)
+		cancel()
+
+		i, e := other.SafeResolveWith(kept)
+		require.NoError(t, e)
+		require.Equal(t, 1, i)
+		require.Equal(t, 1, other.ResolveWith(scope))
+	})
+
+	t.Run("scope travels with the context", func(t *testing.T) {
+		var built int
+		m := submodule.Make[int](func() int {
+			built++
+			return built
+		})
+
+		scope := submodule.CreateScope()
+		ctx := submodule.ContextWithScope(context.Background(), scope)
+
+		found, ok := submodule.ScopeFromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, scope, found)
+
+		require.Equal(t, 1, m.ResolveFrom(ctx))
+		require.Equal(t, 1, m.ResolveWith(scope))
+
+		_, ok = submodule.ScopeFromContext(context.Background())
+		require.False(t, ok)
+
+		i, e := m.SafeResolveFrom(context.Background())
+		require.NoError(t, e)
+		require.Equal(t, i, m.Resolve())
+		require.Equal(t, 2, built)
+	})
+}
+
+type namedDb struct {
+	Name string
+}
+
+func TestNamed(t *testing.T) {
+	primary := submodule.Make[*namedDb](func() *namedDb {
+		return &namedDb{Name: "primary"}
+	}, submodule.WithName("primary"))
+
+	replica := submodule.Make[*namedDb](func() *namedDb {
+		return &namedDb{Name: "replica"}
+	}, submodule.WithName("replica"))
+
+	t.Run("positional parameters pick by name", func(t *testing.T) {
+		repo := submodule.Make[string](func(write *namedDb, read *namedDb) string {
+			return write.Name + "/" + read.Name
+		}, primary, replica, submodule.WithParamNames("replica", "primary"))
+
+		s, e := repo.SafeResolveWith(submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "replica/primary", s)
+	})
+
+	t.Run("In fields pick by tag", func(t *testing.T) {
+		repo := submodule.Make[string](func(p struct {
+			submodule.In
+			Write *namedDb `name:"primary"`
+			Read  *namedDb `name:"replica"`
+			Any   *namedDb
+		}) string {
+			return p.Write.Name + "/" + p.Read.Name + "/" + p.Any.Name
+		}, replica, primary)
+
+		s, e := repo.SafeResolveWith(submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "primary/replica/replica", s)
+	})
+
+	t.Run("Resolve fields pick by tag", func(t *testing.T) {
+		type repo struct {
+			Write *namedDb `name:"primary"`
+			Read  *namedDb `name:"replica"`
+		}
+
+		r, e := submodule.Resolve(&repo{}, replica, primary).SafeResolveWith(submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "primary", r.Write.Name)
+		require.Equal(t, "replica", r.Read.Name)
+	})
+
+	t.Run("named modifiable keeps its name", func(t *testing.T) {
+		cache := submodule.MakeModifiable[*namedDb](func() *namedDb {
+			return &namedDb{Name: "cache"}
+		}, submodule.WithName("cache"))
+
+		user := submodule.Make[string](func(db *namedDb) string {
+			return db.Name
+		}, primary, cache, submodule.WithParamNames("cache"))
+
+		require.Equal(t, "cache", user.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("substitute keeps the name", func(t *testing.T) {
+		db := submodule.Make[*namedDb](func() *namedDb {
+			return &namedDb{Name: "primary"}
+		}, submodule.WithName("primary"))
+
+		repo := submodule.Make[string](func(write *namedDb) string {
+			return write.Name
+		}, replica, db, submodule.WithParamNames("primary"))
+
+		db.Substitute(submodule.Make[*namedDb](func() *namedDb {
+			return &namedDb{Name: "test"}
+		}))
+
+		s, e := repo.SafeResolveWith(submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "test", s)
+	})
+
+	t.Run("missing named provider fails at construction", func(t *testing.T) {
+		assert.PanicsWithValue(t,
+			"unable to resolve dependency for type: func(*submodule_test.namedDb) string. \n Unable to resolve: *submodule_test.namedDb named analytics",
+			func() {
+				submodule.Make[string](func(db *namedDb) string {
+					return db.Name
+				}, primary, replica, submodule.WithParamNames("analytics"))
+			},
+		)
+
+		assert.Panics(t, func() {
+			submodule.Make[string](func(p struct {
+				submodule.In
+				Db *namedDb `name:"analytics"`
+			}) string {
+				return p.Db.Name
+			}, primary, replica)
+		})
+	})
+}
+
+type (
+	strictNamer interface{ Name() string }
+	strictAlice struct{}
+	strictBob   struct{}
+)
+
+func (strictAlice) Name() string { return "alice" }
+func (strictBob) Name() string   { return "bob" }
+
+func TestStrict(t *testing.T) {
+	alice := submodule.Value(strictAlice{})
+	bob := submodule.Value(strictBob{})
+
+	t.Run("exact type wins over assignable ones", func(t *testing.T) {
+		namer := submodule.Make[strictNamer](func() strictNamer {
+			return strictBob{}
+		})
+
+		greeter := submodule.Make[string](func(n strictNamer) string {
+			return n.Name()
+		}, alice, namer, submodule.WithStrict())
+
+		require.Equal(t, "bob", greeter.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("exact type is only preferred in strict mode", func(t *testing.T) {
+		namer := submodule.Make[strictNamer](func() strictNamer {
+			return strictBob{}
+		})
+
+		greeter := submodule.Make[string](func(n strictNamer) string {
+			return n.Name()
+		}, alice, namer)
+
+		require.Equal(t, "alice", greeter.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("modifiers win over defaults, strict or not", func(t *testing.T) {
+		greeter := submodule.MakeModifiable[string](func(n strictNamer) string {
+			return n.Name()
+		}, submodule.Make[strictNamer](func() strictNamer {
+			return strictBob{}
+		}))
+		greeter.Append(alice)
+
+		require.Equal(t, "alice", greeter.ResolveWith(submodule.CreateScope()))
+
+		submodule.SetStrict(true)
+		defer submodule.SetStrict(false)
+
+		name, e := greeter.SafeResolveWith(submodule.CreateScope())
+		require.NoError(t, e)
+		require.Equal(t, "alice", name)
+
+		// ambiguous modifiers fail the resolution, the factory does not panic
+		greeter.Append(bob)
+		_, e = greeter.SafeResolveWith(submodule.CreateScope())
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.AmbiguousDependency, re.Kind)
+
+		var pe *submodule.PanicError
+		assert.False(t, errors.As(e, &pe))
+	})
+
+	t.Run("first match wins unless strict", func(t *testing.T) {
+		greeter := submodule.Make[string](func(n strictNamer) string {
+			return n.Name()
+		}, alice, bob)
+
+		require.Equal(t, "alice", greeter.ResolveWith(submodule.CreateScope()))
+	})
+
+	t.Run("strict submodule fails at construction", func(t *testing.T) {
+		p := func() (p any) {
+			defer func() { p = recover() }()
+			submodule.Make[string](func(n strictNamer) string {
+				return n.Name()
+			}, alice, bob, submodule.WithStrict())
+			return
+		}()
+
+		require.NotNil(t, p)
+		assert.Contains(t, p, "ambiguous dependency for type: func(submodule_test.strictNamer) string. \n submodule_test.strictNamer can be provided by submodule_test.strictAlice (")
+		assert.Contains(t, p, "submodule_test.go")
+	})
+
+	t.Run("strict mode fails at resolution", func(t *testing.T) {
+		greeter := submodule.Make[string](func(p struct {
+			submodule.In
+			Namer strictNamer
+		}) string {
+			return p.Namer.Name()
+		}, alice, bob)
+
+		submodule.SetStrict(true)
+		defer submodule.SetStrict(false)
+
+		_, e := greeter.SafeResolveWith(submodule.CreateScope())
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, e, &re)
+		assert.Equal(t, submodule.AmbiguousDependency, re.Kind)
+		assert.Equal(t, "Namer", re.Path[1].Field)
+		assert.Contains(t, e.Error(), "submodule_test.strictAlice")
+		assert.Contains(t, e.Error(), "submodule_test.strictBob")
+	})
+
+	t.Run("names tell dependencies apart in strict mode", func(t *testing.T) {
+		greeter := submodule.Make[string](func(n strictNamer) string {
+			return n.Name()
+		}, alice, submodule.Make[strictNamer](func() strictNamer {
+			return strictBob{}
+		}, submodule.WithName("bob")), submodule.WithParamNames("bob"), submodule.WithStrict())
+
+		require.Equal(t, "bob", greeter.ResolveWith(submodule.CreateScope()))
+	})
+}
@@ -0,0 +1,100 @@
+package submodule
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Validate walks the dependency graph of the given submodules without calling any factory
+// and reports every problem found at once, joined with errors.Join:
+//   - a value no dependency can provide, as a *ResolutionError of kind MissingDependency
+//   - a value more than one dependency can provide, as a *ResolutionError of kind AmbiguousDependency
+//   - a pointer to an In struct, or an unexported field, which cannot be injected
+//   - a circular dependency, as a *CycleError
+//
+// Each *ResolutionError has the path from a root down to the faulty value.
+// Useful in a unit test or in main before starting, modifiers and substitutions are taken into account.
+// As in resolution, a modifier providing a value overrides the defaults instead of being ambiguous with them
+func Validate(roots ...Retrievable) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var errs []error
+	state := make(map[Retrievable]int)
+	var path []step
+
+	report := func(kind ErrorKind, l link, format string, args ...any) {
+		steps := make([]Step, 0, len(path)+1)
+		for _, s := range path {
+			steps = append(steps, Step{Type: s.r.provides(), Field: s.field})
+		}
+
+		errs = append(errs, &ResolutionError{
+			Kind: kind,
+			Path: append(steps, Step{Type: l.typ, Field: l.field}),
+			Err:  fmt.Errorf(format, args...),
+		})
+	}
+
+	var visit func(r Retrievable, field string, lazy bool)
+	visit = func(r Retrievable, field string, lazy bool) {
+		switch state[r] {
+		case visited:
+			return
+		case visiting:
+			// lazy dependencies are resolved once construction is over, they do not take part in cycles
+			if !lazy {
+				i := slices.IndexFunc(path, func(s step) bool { return s.r == r })
+				errs = append(errs, newCycleError(append(slices.Clip(path[i:]), step{r: r})))
+			}
+			return
+		}
+
+		state[r] = visiting
+		path = append(path, step{r: r, field: field})
+
+		for _, l := range r.links() {
+			switch {
+			case l.unexported:
+				report(MissingDependency, l, "unable to resolve unexported field: %s, field is not exported", l.field)
+				continue
+			case l.typ.Kind() == reflect.Pointer && isInEmbedded(l.typ.Elem()):
+				report(MissingDependency, l, "unable to resolve %s, In structs are injected by value", l.typ)
+				continue
+			case len(l.candidates) > 1:
+				report(AmbiguousDependency, l, "%w", l.ambiguity(l.candidates))
+			case l.target == nil && !l.optional:
+				report(MissingDependency, l, "unable to resolve dependency for type: %s", l.slot.String())
+			}
+
+			if l.target != nil {
+				visit(l.target, l.field, l.lazy)
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[r] = visited
+	}
+
+	for _, r := range roots {
+		visit(r, "", false)
+	}
+
+	return errors.Join(errs...)
+}
+
+// describe names a submodule by its type, its name and where it is declared
+func describe(r Retrievable) string {
+	d := r.provides().String()
+	if name := r.options().name; name != "" {
+		d += " named " + name
+	}
+	if src := r.source(); src.file != "" {
+		d += fmt.Sprintf(" (%s:%d)", src.file, src.line)
+	}
+	return d
+}
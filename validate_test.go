@@ -0,0 +1,122 @@
+package submodule_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	validConfig struct{}
+	validLogger struct{}
+	validServer struct{}
+
+	validNamer interface{ Name() string }
+	validAlice struct{}
+	validBob   struct{}
+
+	validParams struct {
+		submodule.In
+		Config validConfig
+	}
+)
+
+func (validAlice) Name() string { return "alice" }
+func (validBob) Name() string   { return "bob" }
+
+func TestValidate(t *testing.T) {
+	config := submodule.Value(validConfig{})
+
+	t.Run("a sound graph is valid", func(t *testing.T) {
+		logger := submodule.Make[*validLogger](func(c validConfig) *validLogger {
+			return &validLogger{}
+		}, config)
+
+		server := submodule.Make[*validServer](func(p struct {
+			submodule.In
+			Config validConfig
+			Logger submodule.Lazy[*validLogger]
+		}) *validServer {
+			return &validServer{}
+		}, config, logger)
+
+		assert.NoError(t, submodule.Validate(server))
+	})
+
+	t.Run("ambiguous dependencies are reported with every candidate", func(t *testing.T) {
+		greeter := submodule.Make[string](func(n validNamer) string {
+			return n.Name()
+		}, submodule.Value(validAlice{}), submodule.Value(validBob{}))
+
+		err := submodule.Validate(greeter)
+
+		var re *submodule.ResolutionError
+		require.ErrorAs(t, err, &re)
+		assert.Equal(t, submodule.AmbiguousDependency, re.Kind)
+		assert.Equal(t, "submodule_test.validNamer", re.Path[1].Type.String())
+		assert.Contains(t, re.Error(), "submodule_test.validAlice (")
+		assert.Contains(t, re.Error(), "submodule_test.validBob (")
+		assert.Contains(t, re.Error(), "validate_test.go")
+	})
+
+	t.Run("modifiers override defaults without ambiguity", func(t *testing.T) {
+		greeter := submodule.MakeModifiable[string](func(n validNamer) string {
+			return n.Name()
+		}, submodule.Value(validBob{}))
+		greeter.Append(submodule.Value(validAlice{}))
+
+		assert.NoError(t, submodule.Validate(greeter))
+	})
+
+	t.Run("every problem is reported at once", func(t *testing.T) {
+		logger := submodule.MakeModifiable[*validLogger](func(p *validParams, n validNamer) *validLogger {
+			return &validLogger{}
+		})
+
+		server := submodule.Make[*validServer](func(p struct {
+			submodule.In
+			Logger *validLogger
+			config validConfig
+		}) *validServer {
+			return &validServer{}
+		}, logger, config)
+
+		err := submodule.Validate(server)
+		require.Error(t, err)
+
+		var problems []string
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			problems = append(problems, e.Error())
+		}
+
+		assert.Equal(t, []string{
+			"missing dependency resolving *submodule_test.validServer -> Logger *submodule_test.validLogger -> *submodule_test.validParams: unable to resolve *submodule_test.validParams, In structs are injected by value",
+			"missing dependency resolving *submodule_test.validServer -> Logger *submodule_test.validLogger -> submodule_test.validNamer: unable to resolve dependency for type: submodule_test.validNamer",
+			"missing dependency resolving *submodule_test.validServer -> config submodule_test.validConfig: unable to resolve unexported field: config, field is not exported",
+		}, problems)
+	})
+
+	t.Run("cycles are reported", func(t *testing.T) {
+		logger := submodule.MakeModifiable[*validLogger](func(c validConfig) *validLogger {
+			return &validLogger{}
+		}, config)
+
+		server := submodule.Make[*validServer](func(l *validLogger) *validServer {
+			return &validServer{}
+		}, logger)
+
+		logger.Append(submodule.Make[validConfig](func(s *validServer) validConfig {
+			return validConfig{}
+		}, server))
+
+		var ce *submodule.CycleError
+		require.ErrorAs(t, submodule.Validate(server), &ce)
+		assert.Len(t, ce.Path, 4)
+
+		_, err := server.SafeResolveWith(submodule.CreateScope())
+		assert.True(t, errors.As(err, &ce))
+	})
+}
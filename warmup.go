@@ -0,0 +1,125 @@
+package submodule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WarmupStep reports the resolution of a single submodule during Warmup
+type WarmupStep struct {
+	Type reflect.Type
+	Name string
+	// time spent resolving the submodule, its dependencies being resolved already
+	Took time.Duration
+	Err  error
+	// set when a dependency failed, the submodule was not resolved then
+	Skipped bool
+}
+
+// Warmup resolves the given submodules and all their dependencies in the scope up front,
+// dependencies first, and logs each step with the package logger (set SM_DEBUG to see them).
+// Dependencies held by Lazy are left for later, Transient ones are built by their dependents only.
+// Submodules depending on a failed one are skipped. Every failure is reported at once, joined with errors.Join.
+// Roots are warmed up in the given order, list them as they should be resolved
+//
+//	if err := submodule.Warmup(ctx, scope, routes, mhttp.Server); err != nil {
+//	  log.Fatal(err)
+//	}
+func Warmup(ctx context.Context, scope Scope, roots ...Retrievable) error {
+	return WarmupWith(ctx, scope, logStep, roots...)
+}
+
+// WarmupWith is Warmup reporting each step to the given function instead of the package logger
+func WarmupWith(ctx context.Context, scope Scope, report func(WarmupStep), roots ...Retrievable) error {
+	if scope == nil {
+		scope = globalScope
+	}
+
+	g := Inspect(roots...)
+
+	var errs []error
+	failed := make(map[*Node]bool)
+
+	for _, n := range g.order() {
+		// a transient value warmed up alone would be thrown away, its dependents build their own
+		if n.submodule.options().lifetime == Transient {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		step := WarmupStep{Type: n.Type, Name: n.Name}
+
+		for _, e := range g.Dependencies(n) {
+			if failed[e.To] && !e.Lazy {
+				step.Skipped = true
+			}
+		}
+
+		if !step.Skipped {
+			start := time.Now()
+			_, step.Err = n.submodule.retrieve(&frame{scope: trace(scope).scope, ctx: ctx})
+			step.Took = time.Since(start)
+		}
+
+		if step.Skipped || step.Err != nil {
+			failed[n] = true
+		}
+		if step.Err != nil {
+			errs = append(errs, step.Err)
+		}
+
+		report(step)
+	}
+
+	return errors.Join(errs...)
+}
+
+// order lists the nodes dependencies first, following the order of the roots.
+// Lazy dependencies are resolved when used, they are left out
+func (g *Graph) order() []*Node {
+	var nodes []*Node
+	seen := make(map[*Node]bool)
+
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+
+		for _, e := range g.Dependencies(n) {
+			if !e.Lazy {
+				visit(e.To)
+			}
+		}
+		nodes = append(nodes, n)
+	}
+
+	for _, r := range g.Roots {
+		visit(r)
+	}
+	return nodes
+}
+
+func logStep(s WarmupStep) {
+	name := s.Type.String()
+	if s.Name != "" {
+		name = fmt.Sprintf("%s named %s", name, s.Name)
+	}
+
+	switch {
+	case s.Skipped:
+		logger().Warn("warmup skipped, a dependency failed", "submodule", name)
+	case s.Err != nil:
+		logger().Error("warmup failed", "submodule", name, "took", s.Took, "error", s.Err)
+	default:
+		logger().Info("warmed up", "submodule", name, "took", s.Took)
+	}
+}
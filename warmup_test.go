@@ -0,0 +1,125 @@
+package submodule_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/submodule-org/submodule.go/v2"
+)
+
+type (
+	warmConfig struct{}
+	warmDb     struct{}
+	warmCache  struct{}
+	warmServer struct{}
+)
+
+func TestWarmup(t *testing.T) {
+	config := submodule.Value(&warmConfig{})
+
+	db := submodule.Make[*warmDb](func(c *warmConfig) *warmDb {
+		time.Sleep(10 * time.Millisecond)
+		return &warmDb{}
+	}, config)
+
+	t.Run("resolves dependencies first and reports each step", func(t *testing.T) {
+		server := submodule.Make[*warmServer](func(d *warmDb, c *warmConfig) *warmServer {
+			return &warmServer{}
+		}, db, config)
+
+		var steps []submodule.WarmupStep
+		scope := submodule.CreateScope()
+		require.NoError(t, submodule.WarmupWith(context.Background(), scope, func(s submodule.WarmupStep) {
+			steps = append(steps, s)
+		}, server))
+
+		require.Len(t, steps, 3)
+		assert.Equal(t, "*submodule_test.warmConfig", steps[0].Type.String())
+		assert.Equal(t, "*submodule_test.warmDb", steps[1].Type.String())
+		assert.GreaterOrEqual(t, steps[1].Took, 10*time.Millisecond)
+		assert.Equal(t, "*submodule_test.warmServer", steps[2].Type.String())
+
+		g := submodule.Inspect(server).Annotate(scope)
+		for _, n := range g.Nodes {
+			assert.Equal(t, submodule.Resolved, n.State)
+		}
+	})
+
+	t.Run("failures are reported together, dependents are skipped", func(t *testing.T) {
+		errCache := errors.New("cache is down")
+		cache := submodule.Make[*warmCache](func() (*warmCache, error) {
+			return nil, errCache
+		})
+
+		server := submodule.Make[*warmServer](func(d *warmDb, c *warmCache) *warmServer {
+			return &warmServer{}
+		}, db, cache)
+
+		broken := submodule.Make[string](func(c *warmConfig) (string, error) {
+			return "", errors.New("broken")
+		}, config)
+
+		var skipped []string
+		e := submodule.WarmupWith(context.Background(), submodule.CreateScope(), func(s submodule.WarmupStep) {
+			if s.Skipped {
+				skipped = append(skipped, s.Type.String())
+			}
+		}, server, broken)
+
+		assert.ErrorIs(t, e, errCache)
+		assert.ErrorContains(t, e, "broken")
+		assert.Equal(t, []string{"*submodule_test.warmServer"}, skipped)
+	})
+
+	t.Run("lazy dependencies are left for later", func(t *testing.T) {
+		built := false
+		cache := submodule.Make[*warmCache](func() *warmCache {
+			built = true
+			return &warmCache{}
+		})
+
+		server := submodule.Make[*warmServer](func(c submodule.Lazy[*warmCache]) *warmServer {
+			return &warmServer{}
+		}, cache)
+
+		var steps []submodule.WarmupStep
+		require.NoError(t, submodule.WarmupWith(context.Background(), submodule.CreateScope(), func(s submodule.WarmupStep) {
+			steps = append(steps, s)
+		}, server))
+
+		require.Len(t, steps, 1)
+		assert.False(t, built)
+	})
+
+	t.Run("transient dependencies are built by their dependents only", func(t *testing.T) {
+		built := 0
+		cache := submodule.Make[*warmCache](func() *warmCache {
+			built++
+			return &warmCache{}
+		}, submodule.WithLifetime(submodule.Transient))
+
+		server := submodule.Make[*warmServer](func(c *warmCache) *warmServer {
+			return &warmServer{}
+		}, cache)
+
+		var steps []submodule.WarmupStep
+		require.NoError(t, submodule.WarmupWith(context.Background(), submodule.CreateScope(), func(s submodule.WarmupStep) {
+			steps = append(steps, s)
+		}, server))
+
+		require.Len(t, steps, 1)
+		assert.Equal(t, 1, built)
+	})
+
+	t.Run("stops once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		e := submodule.Warmup(ctx, submodule.CreateScope(), db)
+		assert.ErrorIs(t, e, context.Canceled)
+	})
+}